@@ -0,0 +1,90 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"cmp"
+	"errors"
+	"math"
+)
+
+// SumKeys returns the sum of the keys in the priority queue, or the zero key if it is empty.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) SumKeys() K {
+	var sum K
+	for x := range pq.all() {
+		sum += x.key
+	}
+	return sum
+}
+
+// AverageKey returns the arithmetic mean of the keys in the priority queue.
+// Returns an error if the keys are not floating point.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) AverageKey() (K, error) {
+	var zero K
+	if pq.IsEmpty() {
+		return zero, ErrEmpty
+	}
+	switch sum := any(pq.SumKeys()).(type) {
+	case float32:
+		return any(sum / float32(pq.length)).(K), nil
+	case float64:
+		return any(sum / float64(pq.length)).(K), nil
+	}
+	return zero, errors.New("cannot average keys that are not floating point")
+}
+
+// MaxKey returns the maximum key in the priority queue along with the index associated with it,
+// that is the key yielded last by the key ordering of the priority queue.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) MaxKey() (int, K, error) {
+	if pq.IsEmpty() {
+		var zero K
+		return 0, zero, ErrEmpty
+	}
+	x := pq.maxNode()
+	return x.index, x.key, nil
+}
+
+// Histogram returns the number of keys in the priority queue falling in each of the given number
+// of bins of equal width between lo and hi. Keys below lo, including -Inf, are counted in the
+// first bin and keys above hi, including +Inf, in the last bin. Returns an error if the keys
+// are not floating point or if lo and hi do not bound a finite range.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) Histogram(buckets int, lo, hi K) ([]int, error) {
+	if buckets < 1 {
+		return nil, errors.New("illegal argument: number of bins less than one")
+	}
+	l, ok := floatKey(lo)
+	if !ok {
+		return nil, errors.New("cannot bin keys that are not floating point")
+	}
+	h, _ := floatKey(hi)
+	if !(l < h) || math.IsInf(l, 0) || math.IsInf(h, 0) {
+		return nil, errors.New("illegal argument: empty or infinite key range")
+	}
+	counts := make([]int, buckets)
+	for x := range pq.all() {
+		switch k, _ := floatKey(x.key); {
+		case k <= l:
+			counts[0]++
+		case k >= h:
+			counts[buckets-1]++
+		default:
+			// Halving the operands keeps the differences finite over the whole float range.
+			f := (k/2 - l/2) / (h/2 - l/2) * float64(buckets)
+			counts[min(int(f), buckets-1)]++
+		}
+	}
+	return counts, nil
+}
+
+// floatKey converts a floating point key to float64, returns false if the key is not floating point.
+func floatKey[K cmp.Ordered](key K) (float64, bool) {
+	switch k := any(key).(type) {
+	case float32:
+		return float64(k), true
+	case float64:
+		return k, true
+	}
+	return 0, false
+}
@@ -0,0 +1,117 @@
+package heap
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestAggregates(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := pq.SumKeys(); sum != 0 {
+		t.Fatalf("expected sum 0, but got %f", sum)
+	}
+	if _, err := pq.AverageKey(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	if _, _, err := pq.MaxKey(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	keys := map[int]float32{0: 1.5, 3: 4, 4: -2, 7: 8.5, 9: 3}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if sum := pq.SumKeys(); sum != 17 {
+		t.Fatalf("expected sum 17, but got %f", sum)
+	}
+	if avg, err := pq.AverageKey(); err != nil || avg != 4.25 {
+		t.Fatalf("expected average 4.25, but got %f (%v)", avg, err)
+	}
+	if i, k, err := pq.MaxKey(); err != nil || i != 7 || k != 8.5 {
+		t.Fatalf("expected 7 with key 8.5, but got %d with key %f (%v)", i, k, err)
+	}
+	pqs, err := NewIndexFibonacciPQ[string](3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, k := range []string{"b", "c", "a"} {
+		if err := pqs.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pqs.AverageKey(); err == nil {
+		t.Fatal("expected error averaging string keys")
+	}
+	if i, k, err := pqs.MaxKey(); err != nil || i != 1 || k != "c" {
+		t.Fatalf("expected 1 with key c, but got %d with key %s (%v)", i, k, err)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(120)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := pq.Insert(i, float32(i)+0.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts, err := pq.Histogram(4, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for b, n := range counts {
+		if n != 25 {
+			t.Fatalf("expected 25 keys in bin %d, but got %v", b, counts)
+		}
+	}
+	outside := []float32{-10, float32(math.Inf(-1)), 100, 250, float32(math.Inf(1))}
+	for n, k := range outside {
+		if err := pq.Insert(100+n, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts, err = pq.Histogram(4, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{27, 25, 25, 28}; !slices.Equal(counts, expected) {
+		t.Fatalf("expected %v, but got %v", expected, counts)
+	}
+	if _, err := pq.Histogram(0, 0, 100); err == nil {
+		t.Fatal("expected error for no bin")
+	}
+	if _, err := pq.Histogram(4, 100, 100); err == nil {
+		t.Fatal("expected error for an empty key range")
+	}
+	inf := float32(math.Inf(1))
+	for _, r := range [][2]float32{{-inf, 100}, {0, inf}, {-inf, inf}} {
+		if _, err := pq.Histogram(4, r[0], r[1]); err == nil {
+			t.Fatalf("expected error for the infinite key range [%f, %f]", r[0], r[1])
+		}
+	}
+	counts, err = pq.Histogram(3, -math.MaxFloat32, math.MaxFloat32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{1, 103, 1}; !slices.Equal(counts, expected) {
+		t.Fatalf("expected %v over the whole float range, but got %v", expected, counts)
+	}
+	ints, err := NewIndexFibonacciPQ[int](10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ints.Histogram(4, 0, 100); err == nil {
+		t.Fatal("expected error for integer keys")
+	}
+}
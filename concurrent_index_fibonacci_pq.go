@@ -0,0 +1,198 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"cmp"
+	"sync"
+)
+
+// ConcurrentIndexFibonacciPQ struct represents an indexed priority queue of ordered keys
+// that is safe for concurrent use by multiple goroutines.
+// It wraps an IndexFibonacciPQ and guards every operation with a read-write mutex;
+// read-only operations take the read lock.
+type ConcurrentIndexFibonacciPQ[K cmp.Ordered] struct {
+	mu sync.RWMutex
+	pq *IndexFibonacciPQ[K]
+}
+
+// ConcurrentIndexFibonacciMinPQ is an indexed minimum priority queue of float32 keys
+// that is safe for concurrent use by multiple goroutines.
+type ConcurrentIndexFibonacciMinPQ = ConcurrentIndexFibonacciPQ[float32]
+
+// NewConcurrentIndexFibonacciMinPQ initializes an empty concurrent indexed priority queue
+// of float32 keys with indices between 0 and given max-1.
+// Worst case is O(n).
+func NewConcurrentIndexFibonacciMinPQ(max int) (*ConcurrentIndexFibonacciMinPQ, error) {
+	return NewConcurrentIndexFibonacciPQ[float32](max)
+}
+
+// NewConcurrentIndexFibonacciPQ initializes an empty concurrent indexed priority queue
+// with indices between 0 and given max-1.
+// Worst case is O(n).
+func NewConcurrentIndexFibonacciPQ[K cmp.Ordered](max int) (*ConcurrentIndexFibonacciPQ[K], error) {
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	return &ConcurrentIndexFibonacciPQ[K]{pq: pq}, nil
+}
+
+func (pq *ConcurrentIndexFibonacciPQ[K]) String() string {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.String()
+}
+
+// IsEmpty returns true if the priority queue is empty, false if not.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) IsEmpty() bool {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.IsEmpty()
+}
+
+// Contains returns true if i is on the priority queue, false if not.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Contains(i int) bool {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.Contains(i)
+}
+
+// Len returns the number of elements currently on the priority queue.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Len() int {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.Len()
+}
+
+// Insert associates a key with an index.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Insert(i int, key K) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.Insert(i, key)
+}
+
+// MinIndex returns the index associated with the minimum key.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) MinIndex() (int, error) {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.MinIndex()
+}
+
+// MinKey gets the minimum key currently in the queue.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) MinKey() (K, error) {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.MinKey()
+}
+
+// Peek returns the index associated with the minimum key and the minimum key.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Peek() (int, K, error) {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.Peek()
+}
+
+// DelMin deletes minimum key.
+// Worst case is O(log(n)) (amortized).
+func (pq *ConcurrentIndexFibonacciPQ[K]) DelMin() (int, error) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.DelMin()
+}
+
+// KeyOf returns the key associated with index i.
+// Worst case is O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) KeyOf(i int) (K, error) {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.KeyOf(i)
+}
+
+// ChangeKey changes the key associated with index i to the given key.
+// If the given key is greater, worst case is O(log(n)).
+// If the given key is lower, worst case is O(1) (amortized).
+func (pq *ConcurrentIndexFibonacciPQ[K]) ChangeKey(i int, key K) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.ChangeKey(i, key)
+}
+
+// DecreaseKey decreases the key associated with index i to the given key.
+// Worst case is O(1) (amortized).
+func (pq *ConcurrentIndexFibonacciPQ[K]) DecreaseKey(i int, key K) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.DecreaseKey(i, key)
+}
+
+// IncreaseKey increases the key associated with index i to the given key
+// Worst case is O(log(n))
+func (pq *ConcurrentIndexFibonacciPQ[K]) IncreaseKey(i int, key K) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.IncreaseKey(i, key)
+}
+
+// Delete deletes the key associated the given index.
+// Worst case is O(log(n)) (amortized).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Delete(i int) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.Delete(i)
+}
+
+// Union moves all keys of other into the priority queue, leaving other empty.
+// The caller must not use other concurrently.
+// Worst case is O(n) for merging the index arrays, the heaps are melded in O(1).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Union(other *IndexFibonacciPQ[K]) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.Union(other)
+}
+
+// Grow extends the index range of the priority queue to indices between 0 and given newMax-1.
+// Worst case is O(n).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Grow(newMax int) error {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.pq.Grow(newMax)
+}
+
+// Clone returns a deep copy of the priority queue.
+// Worst case is O(n).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Clone() *ConcurrentIndexFibonacciPQ[K] {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return &ConcurrentIndexFibonacciPQ[K]{pq: pq.pq.Clone()}
+}
+
+// Clear removes all keys from the priority queue.
+// Worst case is O(n).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Clear() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.pq.Clear()
+}
+
+// SortedPairs returns the indexes in the priority queue along with their keys in ascending
+// order of keys. The priority queue is not modified.
+// Worst case is O(n log(n)).
+func (pq *ConcurrentIndexFibonacciPQ[K]) SortedPairs() []Pair[K] {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.SortedPairs()
+}
+
+// Slice returns a slice over the indexes in the priority queue in ascending order of keys.
+// Worst case is O(n log(n)).
+func (pq *ConcurrentIndexFibonacciPQ[K]) Slice() []int {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+	return pq.pq.Slice()
+}
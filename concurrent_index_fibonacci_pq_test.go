@@ -0,0 +1,69 @@
+package heap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConcurrentInsertAndDelMin(t *testing.T) {
+	const workers, perWorker = 8, 100
+	pq, err := NewConcurrentIndexFibonacciMinPQ(workers * perWorker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < perWorker; n++ {
+				i := w*perWorker + n
+				if err := pq.Insert(i, float32(i)); err != nil {
+					t.Error(err)
+					return
+				}
+				if !pq.Contains(i) {
+					t.Errorf("expected %d in the queue", i)
+					return
+				}
+				pq.MinKey()
+				pq.Len()
+			}
+		}(w)
+	}
+	wg.Wait()
+	if pq.Len() != workers*perWorker {
+		t.Fatalf("expected pq length %d, but got %d", workers*perWorker, pq.Len())
+	}
+
+	deleted := make([][]int, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				i, err := pq.DelMin()
+				if err != nil {
+					return
+				}
+				deleted[w] = append(deleted[w], i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for _, d := range deleted {
+		for _, i := range d {
+			if seen[i] {
+				t.Fatalf("index %d deleted twice", i)
+			}
+			seen[i] = true
+		}
+	}
+	if len(seen) != workers*perWorker {
+		t.Fatalf("expected %d deleted indexes, but got %d", workers*perWorker, len(seen))
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
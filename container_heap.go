@@ -0,0 +1,67 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// ContainerHeap adapts an indexed priority queue to heap.Interface of the container/heap
+// package, so that code written against heap.Init, heap.Push and heap.Pop can use it.
+// The values pushed and popped are the index and key pairs of the priority queue.
+//
+// heap.Interface assumes an array-backed binary heap: the container/heap functions
+// reorder the elements by positions with Less and Swap. The Fibonacci heap keeps its
+// own order, so Less always returns false and Swap does nothing, leaving the positions
+// unchanged; Pop deletes the minimum key of the priority queue whatever the position.
+// Consequently heap.Fix and heap.Remove, which refer to elements by position, are not
+// supported: use DecreaseKey, ChangeKey or Delete on the priority queue instead.
+type ContainerHeap[K cmp.Ordered] struct {
+	pq *IndexFibonacciPQ[K]
+}
+
+// NewContainerHeap returns an adapter of the given priority queue to heap.Interface.
+// The priority queue remains usable through its own methods.
+// Worst case is O(1).
+func NewContainerHeap[K cmp.Ordered](pq *IndexFibonacciPQ[K]) *ContainerHeap[K] {
+	return &ContainerHeap[K]{pq: pq}
+}
+
+// Len returns the number of elements currently on the priority queue.
+// Worst case is O(1).
+func (h ContainerHeap[K]) Len() int {
+	return h.pq.Len()
+}
+
+// Less returns false, the priority queue orders its keys itself.
+// Worst case is O(1).
+func (h ContainerHeap[K]) Less(i, j int) bool {
+	return false
+}
+
+// Swap does nothing, the elements of the priority queue have no position.
+// Worst case is O(1).
+func (h ContainerHeap[K]) Swap(i, j int) {}
+
+// Push inserts a Pair in the priority queue. Since heap.Interface cannot return an error,
+// it panics with an error if x is not a Pair or if the priority queue rejects it.
+// Worst case is O(1).
+func (h *ContainerHeap[K]) Push(x any) {
+	p, ok := x.(Pair[K])
+	if !ok {
+		panic(fmt.Errorf("illegal argument: %T is not a %T", x, p))
+	}
+	if err := h.pq.Insert(p.Index, p.Key); err != nil {
+		panic(err)
+	}
+}
+
+// Pop deletes the minimum key of the priority queue and returns its Pair.
+// It panics with ErrEmpty if the priority queue is empty.
+// Worst case is O(log(n)) (amortized).
+func (h *ContainerHeap[K]) Pop() any {
+	i, key, err := h.pq.DelMinWithKey()
+	if err != nil {
+		panic(err)
+	}
+	return Pair[K]{Index: i, Key: key}
+}
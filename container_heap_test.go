@@ -0,0 +1,72 @@
+package heap
+
+import (
+	"container/heap"
+	"errors"
+	"testing"
+)
+
+func TestContainerHeap(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.3, 0.9, 0.1}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewContainerHeap(pq)
+	heap.Init(h)
+	heap.Push(h, Pair[float32]{Index: 4, Key: 0.2})
+	heap.Push(h, Pair[float32]{Index: 5, Key: 0.7})
+	if err := pq.DecreaseKey(2, 0.05); err != nil {
+		t.Fatal(err)
+	}
+	if h.Len() != 6 {
+		t.Fatalf("expected length 6, but got %d", h.Len())
+	}
+	expected := []Pair[float32]{{2, 0.05}, {3, 0.1}, {4, 0.2}, {1, 0.3}, {0, 0.5}, {5, 0.7}}
+	for _, e := range expected {
+		p := heap.Pop(h).(Pair[float32])
+		if p != e {
+			t.Fatalf("expected %v, but got %v", e, p)
+		}
+		checkHeap(t, pq)
+	}
+	if h.Len() != 0 || !pq.IsEmpty() {
+		t.Fatalf("expected empty heap, but got length %d", h.Len())
+	}
+}
+
+func TestContainerHeapPanics(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewContainerHeap(pq)
+	testData := []struct {
+		name     string
+		fn       func()
+		expected error
+	}{
+		{"pop empty", func() { heap.Pop(h) }, ErrEmpty},
+		{"push out of range", func() { heap.Push(h, Pair[float32]{Index: 2, Key: 1}) }, ErrIndexOutOfRange},
+		{"push other type", func() { heap.Push(h, 1) }, nil},
+	}
+	for _, tc := range testData {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok {
+					t.Fatalf("%s: expected panic with an error", tc.name)
+				}
+				if tc.expected != nil && !errors.Is(err, tc.expected) {
+					t.Fatalf("%s: expected %v, but got %v", tc.name, tc.expected, err)
+				}
+			}()
+			tc.fn()
+		}()
+	}
+}
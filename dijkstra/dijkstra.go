@@ -0,0 +1,64 @@
+// Package dijkstra computes single-source shortest paths with an indexed Fibonacci priority queue.
+package dijkstra // import "kkn.fi/heap/dijkstra"
+
+import (
+	"fmt"
+	"math"
+
+	"kkn.fi/heap"
+)
+
+// ShortestPaths returns the distances of the shortest paths from the source vertex
+// to every vertex of a directed graph of n vertices numbered between 0 and n-1.
+// Each edge is given as {from, to, weight}; weights must not be negative.
+// The distance of a vertex that is not reachable from the source is +Inf.
+// Worst case is O(e + n log(n)) for e edges.
+func ShortestPaths(n int, edges [][3]float32, source int) ([]float32, error) {
+	if source < 0 || source >= n {
+		return nil, fmt.Errorf("source %d: %w", source, heap.ErrIndexOutOfRange)
+	}
+	adj := make([][]edge, n)
+	for _, e := range edges {
+		from, to := int(e[0]), int(e[1])
+		if float32(from) != e[0] || from < 0 || from >= n || float32(to) != e[1] || to < 0 || to >= n {
+			return nil, fmt.Errorf("edge %v: %w", e, heap.ErrIndexOutOfRange)
+		}
+		if !(e[2] >= 0) {
+			return nil, fmt.Errorf("edge %v: illegal argument: weight is negative or NaN", e)
+		}
+		adj[from] = append(adj[from], edge{to: to, weight: e[2]})
+	}
+	dist := make([]float32, n)
+	for v := range dist {
+		dist[v] = float32(math.Inf(1))
+	}
+	dist[source] = 0
+	pq, err := heap.NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		return nil, err
+	}
+	if err := pq.Insert(source, 0); err != nil {
+		return nil, err
+	}
+	for !pq.IsEmpty() {
+		v, err := pq.DelMin()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range adj[v] {
+			if d := dist[v] + e.weight; d < dist[e.to] {
+				dist[e.to] = d
+				if _, err := pq.Relax(e.to, d); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return dist, nil
+}
+
+// edge is a weighted directed edge of the adjacency list of a vertex.
+type edge struct {
+	to     int
+	weight float32
+}
@@ -0,0 +1,46 @@
+package dijkstra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShortestPaths(t *testing.T) {
+	// Directed graph tinyEWD of Algorithms, 4th Edition, with an unreachable vertex 8.
+	edges := [][3]float32{
+		{4, 5, 0.35}, {5, 4, 0.35}, {4, 7, 0.37}, {5, 7, 0.28},
+		{7, 5, 0.28}, {5, 1, 0.32}, {0, 4, 0.38}, {0, 2, 0.26},
+		{7, 3, 0.39}, {1, 3, 0.29}, {2, 7, 0.34}, {6, 2, 0.40},
+		{3, 6, 0.52}, {6, 0, 0.58}, {6, 4, 0.93}, {8, 0, 0.1},
+	}
+	dist, err := ShortestPaths(9, edges, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inf := float32(math.Inf(1))
+	expected := []float32{0, 1.05, 0.26, 0.99, 0.38, 0.73, 1.51, 0.60, inf}
+	for v := range expected {
+		if dist[v] != expected[v] && math.Abs(float64(dist[v]-expected[v])) > 1e-5 {
+			t.Fatalf("expected distance %.2f to %d, but got %.2f", expected[v], v, dist[v])
+		}
+	}
+}
+
+func TestShortestPathsError(t *testing.T) {
+	testData := []struct {
+		edges  [][3]float32
+		source int
+	}{
+		{nil, 3},
+		{nil, -1},
+		{[][3]float32{{0, 3, 1}}, 0},
+		{[][3]float32{{0, 1.5, 1}}, 0},
+		{[][3]float32{{0, 1, -1}}, 0},
+		{[][3]float32{{0, 1, float32(math.NaN())}}, 0},
+	}
+	for _, testCase := range testData {
+		if _, err := ShortestPaths(3, testCase.edges, testCase.source); err == nil {
+			t.Fatalf("expected error on %v from %d", testCase.edges, testCase.source)
+		}
+	}
+}
@@ -1,2 +1,2 @@
-// Package heap implements indexed minimum Fibonacci priority queue.
+// Package heap implements indexed minimum and maximum Fibonacci priority queues.
 package heap // import "kkn.fi/heap"
@@ -0,0 +1,38 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT returns a Graphviz DOT representation of the heap.
+// Each tree of the root list is drawn as a cluster with edges from parents to children.
+// Nodes are labeled with their index, key and order, marked nodes are drawn in red.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) ToDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph heap {\n")
+	trees := 0
+	pq.walk(pq.head, 0, func(x *node[K], depth int) {
+		if depth == 0 {
+			if trees > 0 {
+				b.WriteString("\t}\n")
+			}
+			fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n", trees)
+			trees++
+		}
+		fmt.Fprintf(&b, "\t\tn%d [label=\"%d: %v (%d)\"", x.index, x.index, x.key, x.order)
+		if x.mark {
+			b.WriteString(",color=red")
+		}
+		b.WriteString("];\n")
+		if x.parent != nil {
+			fmt.Fprintf(&b, "\t\tn%d -> n%d;\n", x.parent.index, x.index)
+		}
+	})
+	if trees > 0 {
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
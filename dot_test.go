@@ -0,0 +1,72 @@
+package heap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToDOT(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	var x *node[float32]
+	for _, n := range pq.nodes {
+		if n != nil && n.parent != nil && n.parent.parent != nil {
+			x = n
+		}
+	}
+	if err := pq.DecreaseKey(x.index, -1); err != nil {
+		t.Fatal(err)
+	}
+	dot := pq.ToDOT()
+	if !strings.HasPrefix(dot, "digraph heap {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("expected a digraph, but got %s", dot)
+	}
+	nodes, edges, clusters, marked := 0, 0, 0, 0
+	for _, line := range strings.Split(dot, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "subgraph cluster_"):
+			clusters++
+		case strings.Contains(line, "->"):
+			edges++
+		case strings.Contains(line, "[label="):
+			nodes++
+			if strings.Contains(line, "color=red") {
+				marked++
+			}
+		}
+	}
+	s := pq.Stats()
+	if nodes != pq.Len() {
+		t.Fatalf("expected %d nodes, but got %d", pq.Len(), nodes)
+	}
+	if edges != pq.Len()-s.Trees {
+		t.Fatalf("expected %d edges, but got %d", pq.Len()-s.Trees, edges)
+	}
+	if clusters != s.Trees {
+		t.Fatalf("expected %d clusters, but got %d", s.Trees, clusters)
+	}
+	if marked != s.Marked || marked == 0 {
+		t.Fatalf("expected %d marked nodes, but got %d", s.Marked, marked)
+	}
+}
+
+func TestToDOTEmpty(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dot := pq.ToDOT(); dot != "digraph heap {\n}\n" {
+		t.Fatalf("expected empty digraph, but got %q", dot)
+	}
+}
@@ -0,0 +1,41 @@
+package heap // import "kkn.fi/heap"
+
+import "context"
+
+// DrainContext repeatedly deletes the minimum key and calls fn with the deleted index and key,
+// until the priority queue is empty, fn returns an error or the context is done.
+// The context is checked before each deletion and its error is returned when done.
+// The error returned by fn is returned as is; the entries not yet deleted remain in the queue.
+// Worst case is O(n log(n)).
+func (pq *IndexFibonacciPQ[K]) DrainContext(ctx context.Context, fn func(index int, key K) error) error {
+	pq.visiting++
+	defer func() { pq.visiting-- }()
+	for !pq.IsEmpty() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		i, key, err := pq.DelMinWithKey()
+		if err != nil {
+			return err
+		}
+		if err := fn(i, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrainSorted deletes every key of the priority queue, returns the indexes in ascending order of keys.
+// Unlike Slice, the priority queue is consumed rather than left unmodified.
+// Worst case is O(n log(n)).
+func (pq *IndexFibonacciPQ[K]) DrainSorted() []int {
+	result := make([]int, 0, pq.length)
+	for !pq.IsEmpty() {
+		i, err := pq.DelMin()
+		if err != nil {
+			break
+		}
+		result = append(result, i)
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package heap
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDrainContext(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(9-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var drained []int
+	err = pq.DrainContext(context.Background(), func(i int, key float32) error {
+		if key != float32(9-i) {
+			t.Fatalf("expected key %d for %d, but got %.1f", 9-i, i, key)
+		}
+		drained = append(drained, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drained) != 10 || drained[0] != 9 || drained[9] != 0 {
+		t.Fatalf("expected indexes in descending order, but got %v", drained)
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestDrainContextCancel(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n := 0
+	err = pq.DrainContext(ctx, func(i int, key float32) error {
+		n++
+		if n == 4 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected %v, but got %v", context.Canceled, err)
+	}
+	if n != 4 || pq.Len() != 6 {
+		t.Fatalf("expected 4 drained and 6 remaining, but got %d and %d", n, pq.Len())
+	}
+	if i, err := pq.MinIndex(); err != nil || i != 4 {
+		t.Fatalf("expected minimum index 4, but got %d (%v)", i, err)
+	}
+}
+
+func TestDrainContextError(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errStop := errors.New("stop")
+	err = pq.DrainContext(context.Background(), func(i int, key float32) error {
+		if i == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("expected %v, but got %v", errStop, err)
+	}
+	if pq.Len() != 7 {
+		t.Fatalf("expected 7 remaining, but got %d", pq.Len())
+	}
+}
+
+func TestDrainSorted(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	drained := pq.DrainSorted()
+	expected := []int{2, 4, 0, 3, 1}
+	if !slices.Equal(drained, expected) {
+		t.Fatalf("expected %v, but got %v", expected, drained)
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+	if drained := pq.DrainSorted(); len(drained) != 0 {
+		t.Fatalf("expected no indexes, but got %v", drained)
+	}
+}
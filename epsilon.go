@@ -0,0 +1,39 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"errors"
+	"math"
+)
+
+// SetKeyEpsilon sets the epsilon within which a changed floating point key equals the current key.
+// DecreaseKey, IncreaseKey and ChangeKey leave a key unchanged rather than fail when the
+// given key is within eps of it. The heap still orders keys exactly, since an ordering that
+// treats nearby keys as equal is not transitive. An epsilon of zero restores exact comparison.
+// Returns an error if the keys are not floating point or eps is negative or NaN.
+func (pq *IndexFibonacciPQ[K]) SetKeyEpsilon(eps K) error {
+	if isNaN(eps) {
+		return ErrNaNKey
+	}
+	var near func(a, b K) bool
+	switch e := any(eps).(type) {
+	case float32:
+		near = func(a, b K) bool {
+			return math.Abs(float64(any(a).(float32)-any(b).(float32))) <= float64(e)
+		}
+	case float64:
+		near = func(a, b K) bool {
+			return math.Abs(any(a).(float64)-any(b).(float64)) <= e
+		}
+	default:
+		return errors.New("cannot set epsilon of keys that are not floating point")
+	}
+	var zero K
+	if eps < zero {
+		return errors.New("illegal argument: epsilon is negative")
+	}
+	if eps == zero {
+		near = nil
+	}
+	pq.near = near
+	return nil
+}
@@ -0,0 +1,139 @@
+package heap
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestKeyEpsilon(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SetKeyEpsilon(1e-3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(1, 0.5005); err != nil {
+		t.Fatal(err)
+	}
+	if !pq.WouldBeMin(0.4995) {
+		t.Fatal("expected key below the minimum to be a new minimum, the heap orders keys exactly")
+	}
+	if err := pq.DecreaseKey(0, 0.5008); err != nil {
+		t.Fatalf("expected decrease within epsilon to succeed, but got %v", err)
+	}
+	if key, _ := pq.KeyOf(0); key != 0.5 {
+		t.Fatalf("expected key 0.5 to be unchanged, but got %f", key)
+	}
+	if err := pq.IncreaseKey(1, 0.4999); err != nil {
+		t.Fatalf("expected increase within epsilon to succeed, but got %v", err)
+	}
+	if key, _ := pq.KeyOf(1); key != 0.5005 {
+		t.Fatalf("expected key 0.5005 to be unchanged, but got %f", key)
+	}
+	if err := pq.DecreaseKey(0, 0.502); !errors.Is(err, ErrKeyNotDecreased) {
+		t.Fatalf("expected %v, but got %v", ErrKeyNotDecreased, err)
+	}
+	checkHeap(t, pq)
+	if err := pq.SetKeyEpsilon(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(0, 0.5008); !errors.Is(err, ErrKeyNotDecreased) {
+		t.Fatalf("expected %v, but got %v", ErrKeyNotDecreased, err)
+	}
+}
+
+func TestKeyEpsilonHeapOrder(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SetKeyEpsilon(0.1); err != nil {
+		t.Fatal(err)
+	}
+	// 5 is a child of the root 1 and the parent of 7: the decreased keys are within
+	// epsilon of each other but not of the root, which must not hide the cut of 7.
+	if err := pq.DecreaseKey(5, 0.91); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(7, 0.82); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if i, err := pq.MinIndex(); err != nil || i != 7 {
+		t.Fatalf("expected minimum 7, but got %d (%v)", i, err)
+	}
+	expectedDel := []int{7, 5, 1, 2, 3, 4, 6, 8}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+		checkHeap(t, pq)
+	}
+}
+
+func TestKeyEpsilonChangeKey(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SetKeyEpsilon(1e-3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []float32{0.4995, 0.5005} {
+		if err := pq.ChangeKey(0, key); err != nil {
+			t.Fatal(err)
+		}
+		if k, _ := pq.KeyOf(0); k != 0.5 {
+			t.Fatalf("expected key 0.5 to be unchanged, but got %f", k)
+		}
+	}
+	if changed, err := pq.Relax(0, 0.4995); changed || err != nil {
+		t.Fatalf("expected no change relaxing within epsilon, but got %t (%v)", changed, err)
+	}
+	if err := pq.ChangeKey(0, 0.4); err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := pq.KeyOf(0); k != 0.4 {
+		t.Fatalf("expected key 0.4, but got %f", k)
+	}
+}
+
+func TestKeyEpsilonError(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ64(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SetKeyEpsilon(-1); err == nil {
+		t.Fatal("expected error on negative epsilon")
+	}
+	if err := pq.SetKeyEpsilon(math.NaN()); err != ErrNaNKey {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	ints, err := NewIndexFibonacciPQ[int](10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ints.SetKeyEpsilon(1); err == nil {
+		t.Fatal("expected error on integer keys")
+	}
+}
@@ -0,0 +1,32 @@
+package heap // import "kkn.fi/heap"
+
+import "errors"
+
+// Errors concerning an index are wrapped with the index, as in "index 3: specified index is
+// not in the queue", and should be compared with errors.Is.
+var (
+	// ErrEmpty is returned when the minimum of an empty priority queue is requested.
+	ErrEmpty = errors.New("priority queue is empty")
+	// ErrIndexOutOfRange is returned when an index is outside the index range of the priority queue.
+	ErrIndexOutOfRange = errors.New("illegal argument: index is out of range")
+	// ErrIndexAbsent is returned when an index is not in the priority queue.
+	ErrIndexAbsent = errors.New("specified index is not in the queue")
+	// ErrIndexPresent is returned when an index is already in the priority queue.
+	ErrIndexPresent = errors.New("specified index is already in the queue")
+	// ErrKeyNotDecreased is returned when DecreaseKey is called with a greater key.
+	ErrKeyNotDecreased = errors.New("calling with this argument would not decrease the key")
+	// ErrKeyNotIncreased is returned when IncreaseKey is called with a lower key.
+	ErrKeyNotIncreased = errors.New("calling with this argument would not increase the key")
+	// ErrNaNKey is returned when a key is NaN. NaN is not ordered with respect to any key.
+	ErrNaNKey = errors.New("key is NaN")
+	// ErrKeyRejected is returned when a key inserted in a full bounded priority queue
+	// is not less than its maximum key.
+	ErrKeyRejected = errors.New("key is not less than the maximum key of the full queue")
+	// ErrLengthMismatch is returned when parallel slices of indexes and keys differ in length.
+	ErrLengthMismatch = errors.New("illegal argument: slices differ in length")
+	// ErrStaleEntry is returned when an IndexEntry is used after its index was deleted.
+	ErrStaleEntry = errors.New("entry is no longer in the queue")
+	// ErrCorruptHeap is returned when an operation fails on a heap whose invariants are broken.
+	// The priority queue must not be used afterwards.
+	ErrCorruptHeap = errors.New("heap is corrupt")
+)
@@ -0,0 +1,209 @@
+package heap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, errMinIndex := pq.MinIndex()
+	_, errMinKey := pq.MinKey()
+	_, _, errPeek := pq.Peek()
+	_, errDelMin := pq.DelMin()
+	if err := pq.Insert(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	_, errKeyOfRange := pq.KeyOf(3)
+	_, errKeyOfAbsent := pq.KeyOf(0)
+	testData := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"MinIndex", errMinIndex, ErrEmpty},
+		{"MinKey", errMinKey, ErrEmpty},
+		{"Peek", errPeek, ErrEmpty},
+		{"DelMin", errDelMin, ErrEmpty},
+		{"Insert out of range", pq.Insert(-1, 0.1), ErrIndexOutOfRange},
+		{"Insert present", pq.Insert(1, 0.1), ErrIndexPresent},
+		{"KeyOf out of range", errKeyOfRange, ErrIndexOutOfRange},
+		{"KeyOf absent", errKeyOfAbsent, ErrIndexAbsent},
+		{"ChangeKey out of range", pq.ChangeKey(3, 0.1), ErrIndexOutOfRange},
+		{"ChangeKey absent", pq.ChangeKey(0, 0.1), ErrIndexAbsent},
+		{"DecreaseKey out of range", pq.DecreaseKey(3, 0.1), ErrIndexOutOfRange},
+		{"DecreaseKey absent", pq.DecreaseKey(0, 0.1), ErrIndexAbsent},
+		{"DecreaseKey greater", pq.DecreaseKey(1, 0.9), ErrKeyNotDecreased},
+		{"IncreaseKey out of range", pq.IncreaseKey(3, 0.1), ErrIndexOutOfRange},
+		{"IncreaseKey absent", pq.IncreaseKey(0, 0.1), ErrIndexAbsent},
+		{"IncreaseKey lower", pq.IncreaseKey(1, 0.1), ErrKeyNotIncreased},
+		{"Delete out of range", pq.Delete(3), ErrIndexOutOfRange},
+		{"Delete absent", pq.Delete(0), ErrIndexAbsent},
+	}
+	for _, testCase := range testData {
+		if !errors.Is(testCase.err, testCase.expected) {
+			t.Errorf("%s: expected %v, but got %v", testCase.name, testCase.expected, testCase.err)
+		}
+	}
+}
+
+func TestErrorsWrapped(t *testing.T) {
+	if _, err := NewIndexFibonacciMinPQFromMap(2, map[int]float32{2: 0.1}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	pq, err := NewIndexFibonacciMinPQFromMap(2, map[int]float32{1: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQFromMap(2, map[int]float32{1: 0.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Union(other); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+}
+
+func TestErrorsWithIndex(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	_, errKeyOf := pq.KeyOf(2)
+	nan := float32(math.NaN())
+	_, errRelax := pq.Relax(2, nan)
+	bounded, err := NewBoundedIndexFibonacciMinPQ(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bounded.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		name     string
+		err      error
+		expected error
+		index    int
+	}{
+		{"Insert out of range", pq.Insert(7, 0.1), ErrIndexOutOfRange, 7},
+		{"Insert present", pq.Insert(1, 0.1), ErrIndexPresent, 1},
+		{"KeyOf absent", errKeyOf, ErrIndexAbsent, 2},
+		{"ChangeKey out of range", pq.ChangeKey(-4, 0.1), ErrIndexOutOfRange, -4},
+		{"ChangeKey absent", pq.ChangeKey(2, 0.1), ErrIndexAbsent, 2},
+		{"DecreaseKey greater", pq.DecreaseKey(1, 0.9), ErrKeyNotDecreased, 1},
+		{"IncreaseKey lower", pq.IncreaseKey(1, 0.1), ErrKeyNotIncreased, 1},
+		{"Delete out of range", pq.Delete(5), ErrIndexOutOfRange, 5},
+		{"Delete absent", pq.Delete(0), ErrIndexAbsent, 0},
+		{"Insert NaN", pq.Insert(2, nan), ErrNaNKey, 2},
+		{"ChangeKey NaN", pq.ChangeKey(1, nan), ErrNaNKey, 1},
+		{"DecreaseKey NaN", pq.DecreaseKey(1, nan), ErrNaNKey, 1},
+		{"IncreaseKey NaN", pq.IncreaseKey(1, nan), ErrNaNKey, 1},
+		{"Relax NaN", errRelax, ErrNaNKey, 2},
+		{"Insert rejected", bounded.Insert(2, 0.9), ErrKeyRejected, 2},
+	}
+	for _, testCase := range testData {
+		if !errors.Is(testCase.err, testCase.expected) {
+			t.Errorf("%s: expected %v, but got %v", testCase.name, testCase.expected, testCase.err)
+			continue
+		}
+		if prefix := fmt.Sprintf("index %d: ", testCase.index); !strings.HasPrefix(testCase.err.Error(), prefix) {
+			t.Errorf("%s: expected message starting with %q, but got %q", testCase.name, prefix, testCase.err)
+		}
+	}
+}
+
+func TestErrCorruptHeap(t *testing.T) {
+	corrupt := []struct {
+		name string
+		op   func(pq *IndexFibonacciMinPQ) error
+	}{
+		{"DelMin", func(pq *IndexFibonacciMinPQ) error {
+			_, err := pq.DelMin()
+			return err
+		}},
+		{"DelMinWithKey", func(pq *IndexFibonacciMinPQ) error {
+			_, _, err := pq.DelMinWithKey()
+			return err
+		}},
+		{"Delete", func(pq *IndexFibonacciMinPQ) error { return pq.Delete(3) }},
+		{"DecreaseKey", func(pq *IndexFibonacciMinPQ) error { return pq.DecreaseKey(3, -1) }},
+		{"IncreaseKey", func(pq *IndexFibonacciMinPQ) error { return pq.IncreaseKey(1, 10) }},
+	}
+	for _, testCase := range corrupt {
+		pq, err := NewIndexFibonacciMinPQ(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			if err := pq.Insert(i, float32(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range pq.nodes {
+			if x != nil {
+				x.next = nil
+			}
+		}
+		if err := testCase.op(pq); !errors.Is(err, ErrCorruptHeap) {
+			t.Fatalf("%s: expected %v, but got %v", testCase.name, ErrCorruptHeap, err)
+		}
+	}
+}
+
+func TestCallbackPanicNotCorrupt(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var seen map[int]bool
+	pq.OnMinChange(func(oldIndex, newIndex int) {
+		seen[newIndex] = true
+	})
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected the panic of the callback to propagate")
+		}
+		if err, ok := r.(error); ok && errors.Is(err, ErrCorruptHeap) {
+			t.Fatalf("expected the panic of the callback, but got %v", err)
+		}
+		if err := pq.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	pq.DelMin()
+}
+
+func TestRecoverOnlyNilDereference(t *testing.T) {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected an index out of range panic to propagate")
+			}
+		}()
+		defer recoverCorrupt(&err)
+		var s []int
+		_ = s[len(s)]
+	}()
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package heap // import "kkn.fi/heap"
+
+import "errors"
+
+// FibonacciMinPQ struct represents a priority queue of arbitrary values with float32 keys.
+// It supports the usual insert and delete-the-minimum operations,
+// along with a decrease-the-key method.
+// Insert returns an Entry handle the client uses to refer to the value
+// when decreasing its key.
+//
+// This implementation uses a Fibonacci heap, the same one IndexFibonacciMinPQ uses.
+// The Insert, Len, IsEmpty and Peek take constant time.
+// The DecreaseKey operation takes amortized constant time.
+// The DelMin takes amortized logarithmic time.
+type FibonacciMinPQ struct {
+	tree[float32]
+}
+
+// Entry is a handle to a value in a FibonacciMinPQ.
+type Entry struct {
+	value any
+	node  *node[float32]
+	pq    *FibonacciMinPQ
+}
+
+// Value returns the value of the entry.
+func (e Entry) Value() any {
+	return e.value
+}
+
+// Key returns the key of the entry, or 0 if the entry has been deleted from the queue.
+func (e Entry) Key() float32 {
+	if e.node == nil {
+		return 0
+	}
+	return e.node.key
+}
+
+// NewFibonacciMinPQ initializes an empty priority queue.
+// Worst case is O(1).
+func NewFibonacciMinPQ() *FibonacciMinPQ {
+	return &FibonacciMinPQ{}
+}
+
+// IsEmpty returns true if the priority queue is empty, false if not.
+// Worst case is O(1).
+func (pq FibonacciMinPQ) IsEmpty() bool {
+	return pq.length == 0
+}
+
+// Len returns the number of elements currently on the priority queue.
+// Worst case is O(1).
+func (pq FibonacciMinPQ) Len() int {
+	return pq.length
+}
+
+// Insert adds a value with a key, returns a handle to the value.
+// The key must not be NaN.
+// Worst case is O(1).
+func (pq *FibonacciMinPQ) Insert(value any, key float32) *Entry {
+	e := &Entry{
+		value: value,
+		pq:    pq,
+	}
+	e.node = &node[float32]{
+		key:   key,
+		value: e,
+	}
+	pq.insert(e.node)
+	return e
+}
+
+// Peek returns the value with the minimum key and the minimum key.
+// Returns nil and 0 if the priority queue is empty.
+// Worst case is O(1).
+func (pq FibonacciMinPQ) Peek() (any, float32) {
+	if pq.IsEmpty() {
+		return nil, 0
+	}
+	return pq.min.value.(*Entry).value, pq.min.key
+}
+
+// DelMin deletes minimum key, returns the value and the key deleted.
+// Returns nil and 0 if the priority queue is empty.
+// Worst case is O(log(n)) (amortized).
+func (pq *FibonacciMinPQ) DelMin() (any, float32) {
+	if pq.IsEmpty() {
+		return nil, 0
+	}
+	x := pq.extractMin()
+	e := x.value.(*Entry)
+	e.node = nil
+	return e.value, x.key
+}
+
+// DecreaseKey decreases the key of the value referred by the given entry to the given key.
+// Worst case is O(1) (amortized).
+func (pq *FibonacciMinPQ) DecreaseKey(e *Entry, key float32) error {
+	if e == nil || e.pq != pq || e.node == nil {
+		return errors.New("specified entry is not in the queue")
+	}
+	if isNaN(key) {
+		return ErrNaNKey
+	}
+	if pq.greater(key, e.node.key) {
+		return ErrKeyNotDecreased
+	}
+	pq.decrease(e.node, key)
+	return nil
+}
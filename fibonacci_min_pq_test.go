@@ -0,0 +1,60 @@
+package heap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFibonacciMinPQ(t *testing.T) {
+	pq := NewFibonacciMinPQ()
+	if v, _ := pq.DelMin(); v != nil {
+		t.Fatalf("expected nil from empty queue, but got %v", v)
+	}
+	values := []string{"e", "b", "h", "a", "f", "c", "g", "d"}
+	entries := make(map[string]*Entry)
+	for i, v := range values {
+		entries[v] = pq.Insert(v, float32(i))
+	}
+	if pq.Len() != 8 {
+		t.Fatalf("expected pq length 8, but got %d", pq.Len())
+	}
+	if v, key := pq.Peek(); v != "e" || key != 0 {
+		t.Fatalf("expected e with key 0, but got %v with key %.1f", v, key)
+	}
+	if v, _ := pq.DelMin(); v != "e" {
+		t.Fatalf("expected e, but got %v", v)
+	}
+	if err := pq.DecreaseKey(entries["e"], -1); err == nil {
+		t.Fatal("expected error on deleted entry")
+	}
+	if err := pq.DecreaseKey(entries["d"], float32(math.NaN())); err != ErrNaNKey {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if err := pq.DecreaseKey(entries["d"], 10); err == nil {
+		t.Fatal("expected error on greater key")
+	}
+	for _, v := range []string{"d", "c", "b", "a"} {
+		if err := pq.DecreaseKey(entries[v], -entries[v].Key()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectedDel := []string{"d", "c", "a", "b", "h", "f", "g"}
+	for _, expected := range expectedDel {
+		v, _ := pq.DelMin()
+		if expected != v {
+			t.Fatalf("expected %s, but got %v", expected, v)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestFibonacciMinPQForeignEntry(t *testing.T) {
+	pq := NewFibonacciMinPQ()
+	other := NewFibonacciMinPQ()
+	e := other.Insert("a", 1)
+	if err := pq.DecreaseKey(e, 0); err == nil {
+		t.Fatal("expected error on entry of another queue")
+	}
+}
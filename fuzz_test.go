@@ -0,0 +1,81 @@
+package heap
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func FuzzIndexFibonacciMinPQ(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1})
+	f.Add([]byte{0, 3, 0, 0, 128, 63, 0, 4, 0, 0, 0, 64, 2, 4, 0, 0, 0, 0, 1, 4, 3, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		const max = 16
+		pq, err := NewIndexFibonacciMinPQ(max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		oracle := make(map[int]float32)
+		for len(data) >= 6 {
+			op, i := data[0]%6, int(int8(data[1]))%(max+2)
+			key := math.Float32frombits(binary.LittleEndian.Uint32(data[2:6]))
+			data = data[6:]
+			old, present := oracle[i]
+			valid := i >= 0 && i < max && !isNaN(key)
+			switch op {
+			case 0:
+				err = pq.Insert(i, key)
+				if valid && !present {
+					oracle[i] = key
+				}
+				valid = valid && !present
+			case 1:
+				err = pq.DecreaseKey(i, key)
+				valid = valid && present && key <= old
+				if valid {
+					oracle[i] = key
+				}
+			case 2:
+				err = pq.IncreaseKey(i, key)
+				valid = valid && present && key >= old
+				if valid {
+					oracle[i] = key
+				}
+			case 3:
+				err = pq.ChangeKey(i, key)
+				valid = valid && present
+				if valid {
+					oracle[i] = key
+				}
+			case 4:
+				err = pq.Delete(i)
+				valid = i >= 0 && i < max && present
+				delete(oracle, i)
+			case 5:
+				var j int
+				j, err = pq.DelMin()
+				valid = len(oracle) > 0
+				if valid {
+					for _, k := range oracle {
+						if k < oracle[j] {
+							t.Fatalf("deleted key %f of %d, but key %f is lower", oracle[j], j, k)
+						}
+					}
+					if _, ok := oracle[j]; !ok {
+						t.Fatalf("deleted absent index %d", j)
+					}
+					delete(oracle, j)
+				}
+			}
+			if valid != (err == nil) {
+				t.Fatalf("operation %d on %d with key %f: unexpected error %v", op, i, key, err)
+			}
+			if err := pq.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if pq.Len() != len(oracle) {
+				t.Fatalf("expected pq length %d, but got %d", len(oracle), pq.Len())
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package heap // import "kkn.fi/heap"
+
+import "cmp"
+
+// IndexEntry is a handle to an index in an IndexFibonacciPQ, returned by InsertEntry.
+// Its methods delegate to the index based methods of the queue without re-passing the index.
+// The handle becomes stale, and its methods return ErrStaleEntry, once the index is deleted
+// from the queue, even if the index is inserted again.
+type IndexEntry[K cmp.Ordered] struct {
+	index int
+	seq   uint64
+	pq    *IndexFibonacciPQ[K]
+}
+
+// InsertEntry associates a key with an index, returns a handle to the index.
+// Worst case is O(1), or O(n) when a bounded priority queue is full.
+func (pq *IndexFibonacciPQ[K]) InsertEntry(i int, key K) (*IndexEntry[K], error) {
+	if err := pq.Insert(i, key); err != nil {
+		return nil, err
+	}
+	return &IndexEntry[K]{
+		index: i,
+		seq:   pq.nodeAt(i).seq,
+		pq:    pq,
+	}, nil
+}
+
+// Index returns the index of the entry.
+func (e IndexEntry[K]) Index() int {
+	return e.index
+}
+
+// IsStale returns true if the index of the entry has been deleted from the queue.
+// Worst case is O(1).
+func (e IndexEntry[K]) IsStale() bool {
+	if !e.pq.inRange(e.index) {
+		return true
+	}
+	x := e.pq.nodeAt(e.index)
+	return x == nil || x.seq != e.seq
+}
+
+// Key returns the key associated with the index of the entry.
+// Worst case is O(1).
+func (e IndexEntry[K]) Key() (K, error) {
+	if e.IsStale() {
+		var zero K
+		return zero, ErrStaleEntry
+	}
+	return e.pq.KeyOf(e.index)
+}
+
+// DecreaseKey decreases the key associated with the index of the entry to the given key.
+// Worst case is O(1) (amortized).
+func (e *IndexEntry[K]) DecreaseKey(key K) error {
+	if e.IsStale() {
+		return ErrStaleEntry
+	}
+	return e.pq.DecreaseKey(e.index, key)
+}
+
+// IncreaseKey increases the key associated with the index of the entry to the given key.
+// Worst case is O(log(n)).
+func (e *IndexEntry[K]) IncreaseKey(key K) error {
+	if e.IsStale() {
+		return ErrStaleEntry
+	}
+	return e.pq.IncreaseKey(e.index, key)
+}
+
+// ChangeKey changes the key associated with the index of the entry to the given key.
+// If the given key is greater, worst case is O(log(n)).
+// If the given key is lower, worst case is O(1) (amortized).
+func (e *IndexEntry[K]) ChangeKey(key K) error {
+	if e.IsStale() {
+		return ErrStaleEntry
+	}
+	return e.pq.ChangeKey(e.index, key)
+}
+
+// Delete deletes the key associated with the index of the entry, making the entry stale.
+// Worst case is O(log(n)) (amortized).
+func (e *IndexEntry[K]) Delete() error {
+	if e.IsStale() {
+		return ErrStaleEntry
+	}
+	return e.pq.Delete(e.index)
+}
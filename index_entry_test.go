@@ -0,0 +1,101 @@
+package heap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIndexEntry(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make([]*IndexEntry[float32], 5)
+	for i := range entries {
+		e, err := pq.InsertEntry(i, float32(i)+0.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[i] = e
+	}
+	if _, err := pq.InsertEntry(0, 0.1); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	if err := entries[3].DecreaseKey(0.2); err != nil {
+		t.Fatal(err)
+	}
+	if err := entries[3].DecreaseKey(0.3); !errors.Is(err, ErrKeyNotDecreased) {
+		t.Fatalf("expected %v, but got %v", ErrKeyNotDecreased, err)
+	}
+	if err := entries[0].IncreaseKey(9); err != nil {
+		t.Fatal(err)
+	}
+	if err := entries[1].ChangeKey(0.1); err != nil {
+		t.Fatal(err)
+	}
+	if k, err := entries[3].Key(); err != nil || k != 0.2 {
+		t.Fatalf("expected 0.2, but got %f (%v)", k, err)
+	}
+	if err := entries[2].Delete(); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	expectedDel := []int{1, 3, 4, 0}
+	for _, expected := range expectedDel {
+		if entries[expected].IsStale() {
+			t.Fatalf("expected entry %d to be valid", expected)
+		}
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestIndexEntryStale(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := pq.InsertEntry(4, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if !e.IsStale() {
+		t.Fatal("expected deleted entry to be stale")
+	}
+	// A reinserted index reuses the freed node but does not revive the entry.
+	if err := pq.Insert(4, 0.7); err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		name string
+		err  error
+	}{
+		{"DecreaseKey", e.DecreaseKey(0.1)},
+		{"IncreaseKey", e.IncreaseKey(0.9)},
+		{"ChangeKey", e.ChangeKey(0.3)},
+		{"Delete", e.Delete()},
+	}
+	for _, tc := range testData {
+		if !errors.Is(tc.err, ErrStaleEntry) {
+			t.Fatalf("%s: expected %v, but got %v", tc.name, ErrStaleEntry, tc.err)
+		}
+	}
+	if _, err := e.Key(); !errors.Is(err, ErrStaleEntry) {
+		t.Fatalf("expected %v, but got %v", ErrStaleEntry, err)
+	}
+	if k, err := pq.KeyOf(4); err != nil || k != 0.7 {
+		t.Fatalf("expected 0.7, but got %f (%v)", k, err)
+	}
+	if e.Index() != 4 {
+		t.Fatalf("expected index 4, but got %d", e.Index())
+	}
+}
@@ -0,0 +1,134 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Lesser is implemented by composite keys that order themselves.
+// Less reports whether the key must be yielded before the other key,
+// that is always a key of the same type.
+type Lesser interface {
+	Less(other any) bool
+}
+
+// IndexFibonacciLesserPQ struct represents an indexed priority queue of keys implementing Lesser.
+// It supports the usual insert and delete-the-minimum operations,
+// along with delete and decrease-the-key methods.
+// It shares the Fibonacci heap implementation of IndexFibonacciMinPQ,
+// comparing the keys with their Less method.
+//
+// The Insert, Len, IsEmpty, Contains, MinIndex, MinKey
+// and KeyOf take constant time.
+// The DecreaseKey operation takes amortized constant time.
+// The Delete and DelMin take amortized logarithmic time.
+// Construction takes time proportional to the specified capacity
+type IndexFibonacciLesserPQ struct {
+	pq *IndexFibonacciMinPQ
+}
+
+// NewIndexFibonacciLesserPQ initializes an empty indexed priority queue with indices between 0 and given max-1.
+// Worst case is O(n).
+func NewIndexFibonacciLesserPQ(max int) (*IndexFibonacciLesserPQ, error) {
+	pq, err := NewIndexFibonacciMinPQ(max)
+	if err != nil {
+		return nil, err
+	}
+	pq.lesser = true
+	return &IndexFibonacciLesserPQ{pq: pq}, nil
+}
+
+// IsEmpty returns true if the priority queue is empty, false if not.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) IsEmpty() bool {
+	return pq.pq.IsEmpty()
+}
+
+// Contains returns true if i is on the priority queue, false if not.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) Contains(i int) bool {
+	return pq.pq.Contains(i)
+}
+
+// Len returns the number of elements currently on the priority queue.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) Len() int {
+	return pq.pq.Len()
+}
+
+// Insert associates a key with an index.
+// Worst case is O(1).
+func (pq *IndexFibonacciLesserPQ) Insert(i int, key Lesser) error {
+	if !pq.pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if pq.pq.Contains(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexPresent)
+	}
+	if key == nil {
+		return errors.New("illegal argument: nil key")
+	}
+	x := pq.pq.newNode(i, 0)
+	x.value = key
+	pq.pq.setNode(i, x)
+	pq.pq.insert(x)
+	return nil
+}
+
+// MinIndex returns the index associated with the minimum key.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) MinIndex() (int, error) {
+	return pq.pq.MinIndex()
+}
+
+// MinKey gets the minimum key currently in the queue.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) MinKey() (Lesser, error) {
+	if pq.pq.IsEmpty() {
+		return nil, ErrEmpty
+	}
+	return pq.pq.min.value.(Lesser), nil
+}
+
+// DelMin deletes minimum key, returns the index associated with it.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciLesserPQ) DelMin() (int, error) {
+	return pq.pq.DelMin()
+}
+
+// KeyOf returns the key associated with index i.
+// Worst case is O(1).
+func (pq IndexFibonacciLesserPQ) KeyOf(i int) (Lesser, error) {
+	if !pq.pq.inRange(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.pq.Contains(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	return pq.pq.nodeAt(i).value.(Lesser), nil
+}
+
+// DecreaseKey decreases the key associated with index i to the given key.
+// Worst case is O(1) (amortized).
+func (pq *IndexFibonacciLesserPQ) DecreaseKey(i int, key Lesser) error {
+	k, err := pq.KeyOf(i)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return errors.New("illegal argument: nil key")
+	}
+	if k.Less(key) {
+		return fmt.Errorf("index %d: %w", i, ErrKeyNotDecreased)
+	}
+	x := pq.pq.nodeAt(i)
+	x.value = key
+	pq.pq.decrease(x, 0)
+	return nil
+}
+
+// Delete deletes the key associated the given index.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciLesserPQ) Delete(i int) error {
+	return pq.pq.Delete(i)
+}
@@ -0,0 +1,73 @@
+package heap
+
+import (
+	"errors"
+	"testing"
+)
+
+type event struct {
+	priority float32
+	stamp    int64
+}
+
+func (e event) Less(other any) bool {
+	o := other.(event)
+	if e.priority != o.priority {
+		return e.priority < o.priority
+	}
+	return e.stamp < o.stamp
+}
+
+func TestLesserInsertAndDelMin(t *testing.T) {
+	pq, err := NewIndexFibonacciLesserPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []event{{0.5, 3}, {0.2, 7}, {0.5, 1}, {0.2, 2}, {0.9, 0}, {0.5, 2}}
+	for i, e := range events {
+		if err := pq.Insert(i, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(0, event{}); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	if err := pq.Insert(6, nil); err == nil {
+		t.Fatal("expected error inserting nil key")
+	}
+	if err := pq.DecreaseKey(4, event{0.5, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(1, event{0.2, 8}); !errors.Is(err, ErrKeyNotDecreased) {
+		t.Fatalf("expected %v, but got %v", ErrKeyNotDecreased, err)
+	}
+	if k, err := pq.KeyOf(4); err != nil || k != (event{0.5, 4}) {
+		t.Fatalf("expected {0.5 4}, but got %v (%v)", k, err)
+	}
+	expectedDel := []int{3, 1, 2, 5, 0, 4}
+	for _, expected := range expectedDel {
+		k, err := pq.MinKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.pq.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+		if k != events[i] && i != 4 {
+			t.Fatalf("expected key %v, but got %v", events[i], k)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Fatalf("expected empty queue, but got length %d", pq.Len())
+	}
+	if _, err := pq.MinKey(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+}
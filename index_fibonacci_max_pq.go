@@ -0,0 +1,116 @@
+package heap // import "kkn.fi/heap"
+
+import "fmt"
+
+// IndexFibonacciMaxPQ struct represents an indexed priority queue of float32 keys
+// supporting delete-the-maximum operation.
+// It is the mirror image of IndexFibonacciMinPQ and shares its Fibonacci heap
+// implementation, ordered from the largest key to the smallest.
+//
+// The Insert, Len, IsEmpty, Contains, MaxIndex, MaxKey
+// and KeyOf take constant time.
+// The IncreaseKey operation takes amortized constant time.
+// The Delete, DecreaseKey, DelMax, ChangeKey take amortized logarithmic time.
+// Construction takes time proportional to the specified capacity
+type IndexFibonacciMaxPQ struct {
+	pq *IndexFibonacciMinPQ
+}
+
+// NewIndexFibonacciMaxPQ initializes an empty indexed maximum priority queue with indices between 0 and given max-1.
+// Worst case is O(n).
+func NewIndexFibonacciMaxPQ(max int) (*IndexFibonacciMaxPQ, error) {
+	pq, err := NewIndexFibonacciPQWithComparator(max, func(a, b float32) bool {
+		return a > b
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &IndexFibonacciMaxPQ{pq: pq}, nil
+}
+
+// IsEmpty returns true if the priority queue is empty, false if not.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) IsEmpty() bool {
+	return pq.pq.IsEmpty()
+}
+
+// Contains returns true if i is on the priority queue, false if not.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) Contains(i int) bool {
+	return pq.pq.Contains(i)
+}
+
+// Len returns the number of elements currently on the priority queue.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) Len() int {
+	return pq.pq.Len()
+}
+
+// Insert associates a key with an index.
+// Worst case is O(1).
+func (pq *IndexFibonacciMaxPQ) Insert(i int, key float32) error {
+	return pq.pq.Insert(i, key)
+}
+
+// MaxIndex returns the index associated with the maximum key.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) MaxIndex() (int, error) {
+	return pq.pq.MinIndex()
+}
+
+// MaxKey gets the maximum key currently in the queue.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) MaxKey() (float32, error) {
+	return pq.pq.MinKey()
+}
+
+// DelMax deletes maximum key.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciMaxPQ) DelMax() (int, error) {
+	return pq.pq.DelMin()
+}
+
+// KeyOf returns the key associated with index i.
+// Worst case is O(1).
+func (pq IndexFibonacciMaxPQ) KeyOf(i int) (float32, error) {
+	return pq.pq.KeyOf(i)
+}
+
+// ChangeKey changes the key associated with index i to the given key.
+// If the given key is lower, worst case is O(log(n)).
+// If the given key is greater, worst case is O(1) (amortized).
+func (pq *IndexFibonacciMaxPQ) ChangeKey(i int, key float32) error {
+	return pq.pq.ChangeKey(i, key)
+}
+
+// IncreaseKey increases the key associated with index i to the given key.
+// Worst case is O(1) (amortized).
+func (pq *IndexFibonacciMaxPQ) IncreaseKey(i int, key float32) error {
+	k, err := pq.pq.KeyOf(i)
+	if err != nil {
+		return err
+	}
+	if k > key {
+		return fmt.Errorf("index %d: %w", i, ErrKeyNotIncreased)
+	}
+	return pq.pq.DecreaseKey(i, key)
+}
+
+// DecreaseKey decreases the key associated with index i to the given key.
+// Worst case is O(log(n))
+func (pq *IndexFibonacciMaxPQ) DecreaseKey(i int, key float32) error {
+	k, err := pq.pq.KeyOf(i)
+	if err != nil {
+		return err
+	}
+	if key > k {
+		return fmt.Errorf("index %d: %w", i, ErrKeyNotDecreased)
+	}
+	return pq.pq.IncreaseKey(i, key)
+}
+
+// Delete deletes the key associated the given index.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciMaxPQ) Delete(i int) error {
+	return pq.pq.Delete(i)
+}
@@ -0,0 +1,77 @@
+package heap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMaxInsertAndDelMax(t *testing.T) {
+	pq, err := NewIndexFibonacciMaxPQ(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pq.Len() != 100 {
+		t.Fatalf("expected pq length 100, but got %d", pq.Len())
+	}
+	var prev float32 = 2
+	for !pq.IsEmpty() {
+		key, err := pq.MaxKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := pq.DelMax()
+		if err != nil {
+			t.Fatalf("delete maximum failed: %v", err)
+		}
+		if key > prev {
+			t.Fatalf("expected key of %d to be at most %f, but got %f", i, prev, key)
+		}
+		prev = key
+	}
+}
+
+func TestMaxChangeKey(t *testing.T) {
+	pq, err := NewIndexFibonacciMaxPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.IncreaseKey(2, 0.1); err == nil {
+		t.Fatal("expected error when increasing to a lower key")
+	}
+	if err := pq.DecreaseKey(2, 0.3); err == nil {
+		t.Fatal("expected error when decreasing to a greater key")
+	}
+	if err := pq.IncreaseKey(2, 0.95); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(9, 0.05); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ChangeKey(0, 0.85); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.MaxIndex(); err != nil || i != 2 {
+		t.Fatalf("expected maximum index 2, but got %d (%v)", i, err)
+	}
+	expectedDel := []int{2, 0, 8, 7, 6, 5, 4, 3, 1, 9}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMax()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
@@ -1,11 +1,19 @@
 package heap // import "kkn.fi/heap"
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 )
 
-// IndexFibonacciMinPQ struct represents an indexed priority queue of float32 keys.
+// IndexFibonacciPQ struct represents an indexed priority queue of ordered keys.
 // It supports the usual insert and delete-the-minimum operations,
 // along with delete and change-the-key methods.
 // In order to let the client refer to keys on the priority queue,
@@ -15,168 +23,689 @@ import (
 // testing if the priority queue is empty, and iterating through
 // the keys.
 //
+// Keys are ordered with < unless the queue was constructed with
+// NewIndexFibonacciPQWithComparator, in which case the minimum is the key
+// that is less than all others according to the comparator.
+//
 // This implementation uses a Fibonacci heap along with an array to associate
-// keys with integers in the given range.
+// keys with integers in the given range. Sparse queues use a map instead of the array.
 // The Insert, Len, IsEmpty, Contains, MinIndex, MinKey
 // and KeyOf take constant time.
 // The DecreaseKey operation takes amortized constant time.
 // The Delete, IncreaseKey, DelMin, ChangeKey take amortized logarithmic time.
 // Construction takes time proportional to the specified capacity
-type IndexFibonacciMinPQ struct {
-	nodes  []*node       // Array of Nodes in the heap
-	head   *node         // Head of the circular root list
-	min    *node         // Minimum Node in the heap
-	length int           // Number of keys in the heap
-	max    int           // Maximum number of elements in the heap
-	table  map[int]*node // Used for the consolidate operation
+type IndexFibonacciPQ[K cmp.Ordered] struct {
+	tree[K]
+	nodes    []*node[K]                   // Array of Nodes in the heap
+	sparse   map[int]*node[K]             // Nodes in the heap by index, replaces the array if not nil
+	max      int                          // Maximum number of elements in the heap
+	recorder io.Writer                    // Receives a line per mutating operation, if not nil
+	pool     *sync.Pool                   // Freed Nodes reused by insertions, if not nil
+	capacity int                          // Maximum number of keys kept by insertions, 0 if unbounded
+	visiting int                          // Number of iterations and drains in progress
+	mapped   bool                         // Accepts any index, the index range is unbounded
+	onMin    func(oldIndex, newIndex int) // Called when the minimum moves to another index, if not nil
+	changing int                          // Number of operations in progress that notify minimum changes
 }
 
-// node represents a node of a tree.
-type node struct {
-	key           float32 // Key of the Node
-	order         int     // The order of the tree rooted by this Node
-	index         int     // Index associated with the key
-	prev, next    *node   // siblings of the Node
-	parent, child *node   // parent and child of this Node
-	mark          bool    // Indicates if this Node already lost a child
-}
+// IndexFibonacciMinPQ is an indexed minimum priority queue of float32 keys.
+type IndexFibonacciMinPQ = IndexFibonacciPQ[float32]
 
-func (pq IndexFibonacciMinPQ) String() string {
-	return fmt.Sprintf("pq{nodes=%v,head=%v}", pq.nodes, pq.head)
+// IndexFibonacciMinPQ64 is an indexed minimum priority queue of float64 keys.
+type IndexFibonacciMinPQ64 = IndexFibonacciPQ[float64]
+
+// Pair is an index and the key associated with it.
+type Pair[K cmp.Ordered] struct {
+	Index int `json:"index"`
+	Key   K   `json:"key"`
 }
 
-func (n node) String() string {
-	return fmt.Sprintf("node{key=%.1f,order=%d,index=%d}", n.key, n.order, n.index)
+// String returns the length, the index range and the minimum of the priority queue
+// along with its index and key pairs in the order of Snapshot.
+// Worst case is O(n log(n)).
+func (pq IndexFibonacciPQ[K]) String() string {
+	if pq.IsEmpty() {
+		return fmt.Sprintf("pq{len=0,max=%d}", pq.max)
+	}
+	return fmt.Sprintf("pq{len=%d,max=%d,min=%d:%v,pairs=%v}", pq.length, pq.max, pq.min.index, pq.min.key, pq.Snapshot())
 }
 
-// NewIndexFibonacciMinPQ initializes an empty indexed priority queue with indices between 0 and given max-1.
+// NewIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1.
 // Worst case is O(n).
 func NewIndexFibonacciMinPQ(max int) (*IndexFibonacciMinPQ, error) {
+	return NewIndexFibonacciPQ[float32](max)
+}
+
+// NewIndexFibonacciMinPQ64 initializes an empty indexed priority queue of float64 keys
+// with indices between 0 and given max-1.
+// Worst case is O(n).
+func NewIndexFibonacciMinPQ64(max int) (*IndexFibonacciMinPQ64, error) {
+	return NewIndexFibonacciPQ[float64](max)
+}
+
+// NewIndexFibonacciPQ initializes an empty indexed priority queue with indices between 0 and given max-1.
+// A priority queue of max 0 rejects every index with ErrIndexOutOfRange until it grows.
+// Worst case is O(n).
+func NewIndexFibonacciPQ[K cmp.Ordered](max int) (*IndexFibonacciPQ[K], error) {
+	return newIndexFibonacciPQ[K](max, false)
+}
+
+// NewSparseIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that allocates memory per key rather than per index.
+// Worst case is O(1).
+func NewSparseIndexFibonacciMinPQ(max int) (*IndexFibonacciMinPQ, error) {
+	return NewSparseIndexFibonacciPQ[float32](max)
+}
+
+// NewSparseIndexFibonacciPQ initializes an empty indexed priority queue with indices between 0
+// and given max-1 that allocates memory per key rather than per index. It suits large index
+// ranges holding few keys; the operations iterating over the indexes in order sort them first.
+// Worst case is O(1).
+func NewSparseIndexFibonacciPQ[K cmp.Ordered](max int) (*IndexFibonacciPQ[K], error) {
+	return newIndexFibonacciPQ[K](max, true)
+}
+
+// NewMappedIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// accepting any integer as an index, see NewMappedIndexFibonacciPQ.
+// Worst case is O(1).
+func NewMappedIndexFibonacciMinPQ() *IndexFibonacciMinPQ {
+	return NewMappedIndexFibonacciPQ[float32]()
+}
+
+// NewMappedIndexFibonacciPQ initializes an empty indexed priority queue accepting any integer
+// as an index, such as a hash value, storing its Nodes in a map like a sparse priority queue.
+// Its index range is unbounded: Cap returns 0 and KeyVector returns nil.
+// Worst case is O(1).
+func NewMappedIndexFibonacciPQ[K cmp.Ordered]() *IndexFibonacciPQ[K] {
+	pq, _ := newIndexFibonacciPQ[K](0, true)
+	pq.mapped = true
+	return pq
+}
+
+// newIndexFibonacciPQ initializes an empty indexed priority queue with indices between 0
+// and given max-1, storing its Nodes in a map if sparse and in an array otherwise.
+func newIndexFibonacciPQ[K cmp.Ordered](max int, sparse bool) (*IndexFibonacciPQ[K], error) {
 	if max < 0 {
 		return nil, errors.New("cannot create a priority queue of negative size")
 	}
-	pq := &IndexFibonacciMinPQ{
-		max:   max,
-		nodes: make([]*node, max),
+	pq := &IndexFibonacciPQ[K]{
+		max:  max,
+		pool: &sync.Pool{},
+	}
+	if sparse {
+		pq.sparse = make(map[int]*node[K])
+	} else {
+		pq.nodes = make([]*node[K], max)
+	}
+	return pq, nil
+}
+
+// NewIndexFibonacciPQWithComparator initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that is ordered by the given less function.
+// The queue yields first the key that is less than all other keys.
+// A nil less function orders keys with <.
+// Worst case is O(n).
+func NewIndexFibonacciPQWithComparator[K cmp.Ordered](max int, less func(a, b K) bool) (*IndexFibonacciPQ[K], error) {
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	pq.less = less
+	return pq, nil
+}
+
+// NewBoundedIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that keeps at most capacity keys.
+// Worst case is O(n).
+func NewBoundedIndexFibonacciMinPQ(max, capacity int) (*IndexFibonacciMinPQ, error) {
+	return NewBoundedIndexFibonacciPQ[float32](max, capacity)
+}
+
+// NewBoundedIndexFibonacciPQ initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that keeps at most capacity keys.
+// When the queue is full, Insert deletes the maximum key to make room for a lower key
+// and rejects other keys with ErrKeyRejected; InsertAll skips them.
+// Worst case is O(n).
+func NewBoundedIndexFibonacciPQ[K cmp.Ordered](max, capacity int) (*IndexFibonacciPQ[K], error) {
+	if capacity < 1 {
+		return nil, errors.New("cannot create a bounded priority queue of capacity less than one")
+	}
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	pq.capacity = capacity
+	return pq, nil
+}
+
+// NewStableIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that yields equal keys in insertion order.
+// Worst case is O(n).
+func NewStableIndexFibonacciMinPQ(max int) (*IndexFibonacciMinPQ, error) {
+	return NewStableIndexFibonacciPQ[float32](max)
+}
+
+// NewStableIndexFibonacciPQ initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that yields equal keys in insertion order.
+// Worst case is O(n).
+func NewStableIndexFibonacciPQ[K cmp.Ordered](max int) (*IndexFibonacciPQ[K], error) {
+	return NewIndexFibonacciPQWithTieBreak[K](max, TieBreakFIFO)
+}
+
+// NewIndexFibonacciMinPQWithTieBreak initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that orders equal keys by the given tie-break policy.
+// Worst case is O(n).
+func NewIndexFibonacciMinPQWithTieBreak(max int, ties TieBreak) (*IndexFibonacciMinPQ, error) {
+	return NewIndexFibonacciPQWithTieBreak[float32](max, ties)
+}
+
+// NewIndexFibonacciPQWithTieBreak initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that orders equal keys by the given tie-break policy.
+// Changing a key does not change the position of the index in the insertion order.
+// Worst case is O(n).
+func NewIndexFibonacciPQWithTieBreak[K cmp.Ordered](max int, ties TieBreak) (*IndexFibonacciPQ[K], error) {
+	if ties < TieBreakNone || ties > TieBreakLIFO {
+		return nil, errors.New("illegal argument: unknown tie-break policy")
+	}
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	pq.ties = ties
+	return pq, nil
+}
+
+// NewLazyIndexFibonacciMinPQ initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that defers consolidations, see NewLazyIndexFibonacciPQ.
+// Worst case is O(n).
+func NewLazyIndexFibonacciMinPQ(max int) (*IndexFibonacciMinPQ, error) {
+	return NewLazyIndexFibonacciPQ[float32](max)
+}
+
+// NewLazyIndexFibonacciPQ initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that defers consolidations.
+// DelMin finds the new minimum by scanning the root list, which is consolidated only when
+// it grows past a logarithmic bound. This lowers the average cost of DelMin at the expense
+// of its worst case.
+// Worst case is O(n).
+func NewLazyIndexFibonacciPQ[K cmp.Ordered](max int) (*IndexFibonacciPQ[K], error) {
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	pq.lazy = true
+	return pq, nil
+}
+
+// NewIndexFibonacciMinPQWithOpCounts initializes an empty indexed priority queue of float32 keys
+// with indices between 0 and given max-1 that counts the operations reshaping its heap.
+// Worst case is O(n).
+func NewIndexFibonacciMinPQWithOpCounts(max int) (*IndexFibonacciMinPQ, error) {
+	return NewIndexFibonacciPQWithOpCounts[float32](max)
+}
+
+// NewIndexFibonacciPQWithOpCounts initializes an empty indexed priority queue with indices
+// between 0 and given max-1 that counts the operations reshaping its heap, see OpCounts.
+// Worst case is O(n).
+func NewIndexFibonacciPQWithOpCounts[K cmp.Ordered](max int) (*IndexFibonacciPQ[K], error) {
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	pq.counts = &opCounts{}
+	return pq, nil
+}
+
+// NewIndexFibonacciMinPQFromMap initializes an indexed priority queue of float32 keys
+// with indices between 0 and given max-1 holding the given keys by index.
+// Worst case is O(n).
+func NewIndexFibonacciMinPQFromMap(max int, keys map[int]float32) (*IndexFibonacciMinPQ, error) {
+	return NewIndexFibonacciPQFromMap(max, keys)
+}
+
+// NewIndexFibonacciPQFromMap initializes an indexed priority queue with indices between 0 and given max-1
+// holding the given keys by index. The root list is built in a single pass.
+// Worst case is O(n).
+func NewIndexFibonacciPQFromMap[K cmp.Ordered](max int, keys map[int]K) (*IndexFibonacciPQ[K], error) {
+	pq, err := NewIndexFibonacciPQ[K](max)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if i < 0 || i >= max {
+			return nil, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+		}
+		if isNaN(key) {
+			return nil, fmt.Errorf("index %d: %w", i, ErrNaNKey)
+		}
+		pq.setNode(i, pq.newNode(i, key))
+	}
+	for x := range pq.all() {
+		if pq.head == nil {
+			pq.head = pq.insertNode(x, nil)
+			pq.min = x
+		} else {
+			pq.insertNode(x, pq.head)
+			if pq.after(pq.min, x) {
+				pq.min = x
+			}
+		}
 	}
+	pq.length = len(keys)
 	return pq, nil
 }
 
 // IsEmpty returns true if the priority queue is empty, false if not.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) IsEmpty() bool {
+func (pq IndexFibonacciPQ[K]) IsEmpty() bool {
 	return pq.length == 0
 }
 
 // Contains returns true if i is on the priority queue, false if not.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) Contains(i int) bool {
-	if i < 0 || i >= pq.max {
+func (pq IndexFibonacciPQ[K]) Contains(i int) bool {
+	if !pq.inRange(i) {
 		return false
 	}
-	return pq.nodes[i] != nil
+	return pq.nodeAt(i) != nil
+}
+
+// ContainsAll returns true if all the given indexes are on the priority queue, false if not.
+// Worst case is O(k) for k indexes.
+func (pq IndexFibonacciPQ[K]) ContainsAll(indices ...int) bool {
+	for _, i := range indices {
+		if !pq.Contains(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny returns true if any of the given indexes is on the priority queue, false if not.
+// Worst case is O(k) for k indexes.
+func (pq IndexFibonacciPQ[K]) ContainsAny(indices ...int) bool {
+	for _, i := range indices {
+		if pq.Contains(i) {
+			return true
+		}
+	}
+	return false
 }
 
 // Len returns the number of elements currently on the priority queue.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) Len() int {
+func (pq IndexFibonacciPQ[K]) Len() int {
 	return pq.length
 }
 
-// Insert associates a key with an index.
+// Cap returns the size of the index range of the priority queue.
 // Worst case is O(1).
-func (pq *IndexFibonacciMinPQ) Insert(i int, key float32) error {
-	if i < 0 || i >= pq.max {
-		return errors.New("illegal argument")
+func (pq IndexFibonacciPQ[K]) Cap() int {
+	return pq.max
+}
+
+// inRange returns true if i is within the index range of the priority queue.
+func (pq IndexFibonacciPQ[K]) inRange(i int) bool {
+	return pq.mapped || (i >= 0 && i < pq.max)
+}
+
+// Insert associates a key with an index.
+// Worst case is O(1), or O(n) when a bounded priority queue is full.
+func (pq *IndexFibonacciPQ[K]) Insert(i int, key K) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if pq.Contains(i) {
-		return errors.New("specified index is already in the queue")
+		return fmt.Errorf("index %d: %w", i, ErrIndexPresent)
 	}
-	x := &node{
-		key:   key,
-		index: i,
+	if isNaN(key) {
+		return fmt.Errorf("index %d: %w", i, ErrNaNKey)
 	}
-	pq.nodes[i] = x
-	pq.length++
-	pq.head = pq.insertNode(x, pq.head)
-	if pq.min == nil {
-		pq.min = pq.head
-	} else {
-		if greater(pq.min.key, key) {
-			pq.min = pq.head
+	if !pq.makeRoom(key) {
+		return fmt.Errorf("index %d: %w", i, ErrKeyRejected)
+	}
+	x := pq.newNode(i, key)
+	pq.setNode(i, x)
+	pq.insert(x)
+	pq.record("insert %d %v", i, key)
+	return nil
+}
+
+// InsertAll associates the keys of the given pairs with their indexes.
+// All pairs are validated before any key is inserted; on error no key is inserted.
+// A full bounded priority queue skips the keys that Insert would reject.
+// Worst case is O(k) for k pairs, or O(kn) when a bounded priority queue is full.
+func (pq *IndexFibonacciPQ[K]) InsertAll(pairs []Pair[K]) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	seen := make(map[int]bool, len(pairs))
+	for _, p := range pairs {
+		if !pq.inRange(p.Index) {
+			return fmt.Errorf("index %d: %w", p.Index, ErrIndexOutOfRange)
+		}
+		if pq.Contains(p.Index) || seen[p.Index] {
+			return fmt.Errorf("index %d: %w", p.Index, ErrIndexPresent)
+		}
+		if isNaN(p.Key) {
+			return fmt.Errorf("index %d: %w", p.Index, ErrNaNKey)
+		}
+		seen[p.Index] = true
+	}
+	for _, p := range pairs {
+		if !pq.makeRoom(p.Key) {
+			continue
 		}
+		x := pq.newNode(p.Index, p.Key)
+		pq.setNode(p.Index, x)
+		pq.insert(x)
+		pq.record("insert %d %v", p.Index, p.Key)
 	}
 	return nil
 }
 
+// InsertColumns associates the keys with the indexes at the same positions of the given slices.
+// Returns ErrLengthMismatch if the slices are not of equal length. Otherwise it inserts the keys
+// like InsertAll: all indexes are validated before any key is inserted.
+// Worst case is O(k) for k keys, or O(kn) when a bounded priority queue is full.
+func (pq *IndexFibonacciPQ[K]) InsertColumns(indices []int, keys []K) error {
+	if len(indices) != len(keys) {
+		return fmt.Errorf("%d indexes and %d keys: %w", len(indices), len(keys), ErrLengthMismatch)
+	}
+	pairs := make([]Pair[K], len(indices))
+	for n, i := range indices {
+		pairs[n] = Pair[K]{Index: i, Key: keys[n]}
+	}
+	return pq.InsertAll(pairs)
+}
+
 // MinIndex returns the index associated with the minimum key.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) MinIndex() (int, error) {
+func (pq IndexFibonacciPQ[K]) MinIndex() (int, error) {
 	if pq.IsEmpty() {
-		return 0, errors.New("priority queue is empty")
+		return 0, ErrEmpty
 	}
 	return pq.min.index, nil
 }
 
+// SecondMin returns the index associated with the second minimum key and the second minimum key.
+// The second minimum is either a root or a child of the minimum, so only these are scanned.
+// Worst case is O(r + c) for r roots and c children of the minimum.
+func (pq IndexFibonacciPQ[K]) SecondMin() (int, K, error) {
+	if pq.length < 2 {
+		var zero K
+		return 0, zero, errors.New("priority queue has fewer than two keys")
+	}
+	var second *node[K]
+	visit := func(head *node[K]) {
+		x := head
+		for ok := true; ok; ok = (x != head) {
+			if x != pq.min && (second == nil || pq.after(second, x)) {
+				second = x
+			}
+			x = x.next
+		}
+	}
+	visit(pq.head)
+	if pq.min.child != nil {
+		visit(pq.min.child)
+	}
+	return second.index, second.key, nil
+}
+
+// MinIndices returns every index associated with a key equal to the minimum key,
+// in ascending order.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) MinIndices() ([]int, error) {
+	if pq.IsEmpty() {
+		return nil, ErrEmpty
+	}
+	var result []int
+	pq.ForEach(func(i int, key K) bool {
+		if !pq.greater(key, pq.min.key) {
+			result = append(result, i)
+		}
+		return true
+	})
+	return result, nil
+}
+
+// IndicesWithKey returns the indexes associated with keys exactly equal to the given key,
+// in ascending order. The result is empty if no index holds the key.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) IndicesWithKey(key K) []int {
+	result := []int{}
+	for x := range pq.all() {
+		if x.key == key {
+			result = append(result, x.index)
+		}
+	}
+	return result
+}
+
+// InRange returns the indexes associated with keys between lo and hi, inclusive.
+// Keys are compared with < regardless of the ordering of the priority queue.
+// The order of the indexes is unspecified.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) InRange(lo, hi K) []int {
+	result := []int{}
+	for x := range pq.all() {
+		if !(x.key < lo) && !(hi < x.key) {
+			result = append(result, x.index)
+		}
+	}
+	return result
+}
+
 // MinKey gets the minimum key currently in the queue.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) MinKey() (float32, error) {
+func (pq IndexFibonacciPQ[K]) MinKey() (K, error) {
 	if pq.IsEmpty() {
-		return 0, errors.New("priority queue is empty")
+		var zero K
+		return zero, ErrEmpty
 	}
 	return pq.min.key, nil
 }
 
+// MinIndexOr returns the index associated with the minimum key,
+// or the given default if the priority queue is empty.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) MinIndexOr(def int) int {
+	if pq.IsEmpty() {
+		return def
+	}
+	return pq.min.index
+}
+
+// MinKeyOr returns the minimum key currently in the queue,
+// or the given default if the priority queue is empty.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) MinKeyOr(def K) K {
+	if pq.IsEmpty() {
+		return def
+	}
+	return pq.min.key
+}
+
+// WouldBeMin returns true if the priority queue is empty or the given key is less
+// than the minimum key, false if not.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) WouldBeMin(key K) bool {
+	return pq.IsEmpty() || pq.greater(pq.min.key, key)
+}
+
+// Peek returns the index associated with the minimum key and the minimum key.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) Peek() (index int, key K, err error) {
+	if pq.IsEmpty() {
+		return 0, key, ErrEmpty
+	}
+	return pq.min.index, pq.min.key, nil
+}
+
+// MinInfo returns the index associated with the minimum key, the minimum key
+// and the order of the tree rooted by the minimum.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) MinInfo() (index int, key K, order int, err error) {
+	if pq.IsEmpty() {
+		return 0, key, 0, ErrEmpty
+	}
+	return pq.min.index, pq.min.key, pq.min.order, nil
+}
+
 // DelMin deletes minimum key.
 // Worst case is O(log(n)) (amortized).
-func (pq *IndexFibonacciMinPQ) DelMin() (int, error) {
+func (pq *IndexFibonacciPQ[K]) DelMin() (_ int, err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
 	if pq.IsEmpty() {
-		return 0, errors.New("priority queue is empty")
-	}
-	pq.head = pq.cutNode(pq.min, pq.head)
-	x := pq.min.child
-	index := pq.min.index
-	if x != nil {
-		for ok := true; ok; ok = (*x != *pq.min.child) {
-			x.parent = nil
-			x = x.next
-		}
-		pq.head = pq.meld(pq.head, x)
-		pq.min.child = nil // For garbage collection
+		return 0, ErrEmpty
 	}
-	pq.length--
-	if !pq.IsEmpty() {
-		pq.consolidate()
-	} else {
-		pq.min = nil
+	x := pq.extractMin()
+	i := x.index
+	pq.setNode(i, nil)
+	pq.freeNode(x)
+	pq.record("delmin")
+	return i, nil
+}
+
+// DelMinWithKey deletes minimum key, returns the index associated with it and the key deleted.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) DelMinWithKey() (index int, key K, err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if pq.IsEmpty() {
+		return 0, key, ErrEmpty
+	}
+	x := pq.extractMin()
+	index, key = x.index, x.key
+	pq.setNode(index, nil)
+	pq.freeNode(x)
+	pq.record("delmin")
+	return index, key, nil
+}
+
+// ReplaceMin deletes the minimum key and associates the given key with the given index,
+// returns the index associated with the deleted key and the key deleted.
+// The new index must not be on the priority queue. Unlike DelMin followed by Insert,
+// the root list is consolidated only once.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) ReplaceMin(newIndex int, newKey K) (oldIndex int, oldKey K, err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if pq.IsEmpty() {
+		return 0, oldKey, ErrEmpty
+	}
+	if !pq.inRange(newIndex) {
+		return 0, oldKey, fmt.Errorf("index %d: %w", newIndex, ErrIndexOutOfRange)
+	}
+	if pq.Contains(newIndex) {
+		return 0, oldKey, fmt.Errorf("index %d: %w", newIndex, ErrIndexPresent)
+	}
+	if isNaN(newKey) {
+		return 0, oldKey, fmt.Errorf("index %d: %w", newIndex, ErrNaNKey)
+	}
+	y := pq.newNode(newIndex, newKey)
+	x := pq.replaceMin(y)
+	oldIndex, oldKey = x.index, x.key
+	pq.setNode(oldIndex, nil)
+	pq.setNode(newIndex, y)
+	pq.freeNode(x)
+	pq.record("delmin")
+	pq.record("insert %d %v", newIndex, newKey)
+	return oldIndex, oldKey, nil
+}
+
+// DelMinIf deletes the minimum key if pred returns true for it, returns the index associated
+// with it, the key deleted and true. Otherwise the priority queue is not modified and ok is false.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) DelMinIf(pred func(key K) bool) (index int, key K, ok bool, err error) {
+	if pred == nil {
+		return 0, key, false, errors.New("illegal argument: nil predicate")
+	}
+	if pq.IsEmpty() {
+		return 0, key, false, ErrEmpty
+	}
+	if !pred(pq.min.key) {
+		return 0, key, false, nil
+	}
+	index, key, err = pq.DelMinWithKey()
+	if err != nil {
+		return 0, key, false, err
+	}
+	return index, key, true, nil
+}
+
+// DelMinBatch deletes up to n minimum keys, returns the indexes associated with them and
+// the keys deleted in ascending order. It stops early if the queue empties.
+// The root list is consolidated only when it grows past a logarithmic bound,
+// instead of after every deletion.
+// Worst case is O(n*log(m)) (amortized).
+func (pq *IndexFibonacciPQ[K]) DelMinBatch(n int) (indexes []int, keys []K, err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if n < 0 {
+		return nil, nil, errors.New("illegal argument: batch size is negative")
+	}
+	n = min(n, pq.Len())
+	indexes = make([]int, 0, n)
+	keys = make([]K, 0, n)
+	limit := pq.rootLimit()
+	for range n {
+		x := pq.extractMinLazy(limit)
+		indexes = append(indexes, x.index)
+		keys = append(keys, x.key)
+		pq.setNode(x.index, nil)
+		pq.freeNode(x)
+		pq.record("delmin")
 	}
-	pq.nodes[index] = nil
-	return index, nil
+	return indexes, keys, nil
+}
+
+// DelMax deletes the maximum key, returns the index associated with it.
+// The heap is not ordered for access to the maximum, so all keys are scanned.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) DelMax() (int, error) {
+	if pq.IsEmpty() {
+		return 0, ErrEmpty
+	}
+	i := pq.maxNode().index
+	if err := pq.Delete(i); err != nil {
+		return 0, err
+	}
+	return i, nil
 }
 
 // KeyOf returns the key associated with index i.
 // Worst case is O(1).
-func (pq IndexFibonacciMinPQ) KeyOf(i int) (float32, error) {
-	if i < 0 || i >= pq.max {
-		return 0, errors.New("illegal argument")
+func (pq IndexFibonacciPQ[K]) KeyOf(i int) (K, error) {
+	var zero K
+	if !pq.inRange(i) {
+		return zero, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if !pq.Contains(i) {
-		return 0, errors.New("specified index is not in the queue")
+		return zero, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
 	}
-	return pq.nodes[i].key, nil
+	return pq.nodeAt(i).key, nil
 }
 
 // ChangeKey changes the key associated with index i to the given key.
 // If the given key is greater, worst case is O(log(n)).
 // If the given key is lower, worst case is O(1) (amortized).
-func (pq *IndexFibonacciMinPQ) ChangeKey(i int, key float32) error {
-	if i < 0 || i >= pq.max {
-		return errors.New("illegal argument")
+func (pq *IndexFibonacciPQ[K]) ChangeKey(i int, key K) error {
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if !pq.Contains(i) {
-		return errors.New("specified index is not in the queue")
+		return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
 	}
-	if greater(key, pq.nodes[i].key) {
+	if isNaN(key) {
+		return fmt.Errorf("index %d: %w", i, ErrNaNKey)
+	}
+	if pq.nearlyEqual(key, pq.nodeAt(i).key) {
+		return nil
+	}
+	if pq.greater(key, pq.nodeAt(i).key) {
 		if err := pq.IncreaseKey(i, key); err != nil {
 			return err
 		}
@@ -188,204 +717,955 @@ func (pq *IndexFibonacciMinPQ) ChangeKey(i int, key float32) error {
 	return nil
 }
 
+// SwapKeys exchanges the keys associated with indexes i and j.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) SwapKeys(i, j int) error {
+	for _, k := range []int{i, j} {
+		if !pq.inRange(k) {
+			return fmt.Errorf("index %d: %w", k, ErrIndexOutOfRange)
+		}
+		if !pq.Contains(k) {
+			return fmt.Errorf("index %d: %w", k, ErrIndexAbsent)
+		}
+	}
+	ki, kj := pq.nodeAt(i).key, pq.nodeAt(j).key
+	if err := pq.ChangeKey(i, kj); err != nil {
+		return err
+	}
+	return pq.ChangeKey(j, ki)
+}
+
+// Set associates the given key with index i, inserting i if it is not on the priority queue
+// and changing its key otherwise.
+// If i is inserted or the given key is lower, worst case is O(1) (amortized).
+// If the given key is greater, worst case is O(log(n)).
+func (pq *IndexFibonacciPQ[K]) Set(i int, key K) error {
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if pq.nodeAt(i) == nil {
+		return pq.Insert(i, key)
+	}
+	return pq.ChangeKey(i, key)
+}
+
+// Relax inserts index i with the given key if it is not on the priority queue, or decreases
+// its key if the given key is lower, as the relaxation of an edge in Dijkstra's algorithm.
+// A key that is not lower leaves the priority queue unchanged. Returns true if a key changed.
+// Worst case is O(1) (amortized).
+func (pq *IndexFibonacciPQ[K]) Relax(i int, key K) (changed bool, err error) {
+	if !pq.inRange(i) {
+		return false, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if isNaN(key) {
+		return false, fmt.Errorf("index %d: %w", i, ErrNaNKey)
+	}
+	x := pq.nodeAt(i)
+	if x == nil {
+		err = pq.Insert(i, key)
+	} else if pq.greater(x.key, key) && !pq.nearlyEqual(key, x.key) {
+		err = pq.DecreaseKey(i, key)
+	} else {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // DecreaseKey decreases the key associated with index i to the given key.
+// A key equal to the current key, within the key epsilon, leaves the current key unchanged.
+// A key equal to the minimum key leaves the minimum unchanged, unless the tie-break
+// policy of the priority queue orders index i first.
 // Worst case is O(1) (amortized).
-func (pq *IndexFibonacciMinPQ) DecreaseKey(i int, key float32) error {
-	if i < 0 || i >= pq.max {
-		return errors.New("illegal argument")
+func (pq *IndexFibonacciPQ[K]) DecreaseKey(i int, key K) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if !pq.Contains(i) {
-		return errors.New("specified index is not in the queue")
+		return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	if isNaN(key) {
+		return fmt.Errorf("index %d: %w", i, ErrNaNKey)
+	}
+	x := pq.nodeAt(i)
+	if pq.nearlyEqual(key, x.key) {
+		return nil
+	}
+	if pq.greater(key, x.key) {
+		return fmt.Errorf("index %d: %w", i, ErrKeyNotDecreased)
 	}
-	if greater(key, pq.nodes[i].key) {
-		return errors.New("calling with this argument would not decrease the key")
+	if !pq.greater(x.key, key) {
+		return nil
 	}
-	x := pq.nodes[i]
-	x.key = key
-	if greater(pq.min.key, key) {
-		pq.min = x
+	pq.decrease(x, key)
+	pq.record("decrease %d %v", i, key)
+	return nil
+}
+
+// DecreaseKeys decreases the keys associated with the indexes of the given map to the mapped keys.
+// All updates are validated before any key is changed; on error no key is changed.
+// The minimum is updated once after all keys are decreased.
+// Worst case is O(k) (amortized) for k updates.
+func (pq *IndexFibonacciPQ[K]) DecreaseKeys(updates map[int]K) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	for i, key := range updates {
+		if !pq.inRange(i) {
+			return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+		}
+		if !pq.Contains(i) {
+			return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+		}
+		if isNaN(key) {
+			return fmt.Errorf("index %d: %w", i, ErrNaNKey)
+		}
+		if x := pq.nodeAt(i); pq.greater(key, x.key) && !pq.nearlyEqual(key, x.key) {
+			return fmt.Errorf("index %d: %w", i, ErrKeyNotDecreased)
+		}
 	}
-	if x.parent != nil && greater(x.parent.key, key) {
-		pq.cut(i)
+	min := pq.min
+	for i, key := range updates {
+		x := pq.nodeAt(i)
+		if pq.nearlyEqual(key, x.key) {
+			continue
+		}
+		x.key = key
+		if x.parent != nil && pq.after(x.parent, x) {
+			pq.cut(x)
+		}
+		if pq.after(min, x) {
+			min = x
+		}
+		pq.record("decrease %d %v", i, key)
 	}
+	pq.min = min
 	return nil
 }
 
 // IncreaseKey increases the key associated with index i to the given key
+// A key equal to the current key, within the key epsilon, leaves the current key unchanged.
 // Worst case is O(log(n))
-func (pq *IndexFibonacciMinPQ) IncreaseKey(i int, key float32) error {
-	if i < 0 || i >= pq.max {
-		return errors.New("illegal argument")
+func (pq *IndexFibonacciPQ[K]) IncreaseKey(i int, key K) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if !pq.Contains(i) {
-		return errors.New("specified index is not in the queue")
+		return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
 	}
-	if greater(pq.nodes[i].key, key) {
-		return errors.New("calling with this argument would not increase the key")
+	if isNaN(key) {
+		return fmt.Errorf("index %d: %w", i, ErrNaNKey)
 	}
-	if err := pq.Delete(i); err != nil {
-		return err
+	x := pq.nodeAt(i)
+	if pq.nearlyEqual(key, x.key) {
+		return nil
 	}
-	if err := pq.Insert(i, key); err != nil {
-		return err
+	if pq.greater(x.key, key) {
+		return fmt.Errorf("index %d: %w", i, ErrKeyNotIncreased)
 	}
+	if !pq.greater(key, x.key) {
+		return nil
+	}
+	pq.increase(x, key)
+	pq.record("increase %d %v", i, key)
 	return nil
 }
 
+// BumpMin increases the minimum key to the given key, returns the index associated with
+// the new minimum key. Only the children of the minimum that hold a lower key than the given key
+// are moved to the root list before the new minimum is found.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) BumpMin(key K) (int, error) {
+	if pq.IsEmpty() {
+		return 0, ErrEmpty
+	}
+	if err := pq.IncreaseKey(pq.min.index, key); err != nil {
+		return 0, err
+	}
+	return pq.min.index, nil
+}
+
 // Delete deletes the key associated the given index.
 // Worst case is O(log(n)) (amortized).
-func (pq *IndexFibonacciMinPQ) Delete(i int) error {
-	if i < 0 || i >= pq.max {
-		return errors.New("illegal argument")
+func (pq *IndexFibonacciPQ[K]) Delete(i int) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer recoverCorrupt(&err)
+	if !pq.inRange(i) {
+		return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
 	}
 	if !pq.Contains(i) {
-		return errors.New("specified index is not in the queue")
+		return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
 	}
-	x := pq.nodes[i]
-	if x.parent != nil {
-		pq.cut(i)
+	x := pq.nodeAt(i)
+	pq.remove(x)
+	pq.setNode(i, nil)
+	pq.freeNode(x)
+	pq.record("delete %d", i)
+	return nil
+}
+
+// Remove deletes the key associated with the given index, returns the key deleted.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) Remove(i int) (K, error) {
+	key, err := pq.KeyOf(i)
+	if err != nil {
+		return key, err
 	}
-	pq.head = pq.cutNode(x, pq.head)
-	if x.child != nil {
-		child := x.child
-		x.child = nil // For garbage collection
-		x = child
-		for ok := true; ok; ok = (*child != *x) {
-			child.parent = nil
-			child = child.next
+	if err := pq.Delete(i); err != nil {
+		var zero K
+		return zero, err
+	}
+	return key, nil
+}
+
+// DeleteAll deletes the keys associated with the given indexes.
+// All indexes are validated before any key is deleted; on error no key is deleted.
+// The heap is consolidated once after all keys are deleted.
+// Worst case is O(k log(n)) (amortized) for k indexes.
+func (pq *IndexFibonacciPQ[K]) DeleteAll(indices []int) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	seen := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if !pq.inRange(i) {
+			return fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+		}
+		if !pq.Contains(i) || seen[i] {
+			return fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
 		}
-		pq.head = pq.meld(pq.head, child)
+		seen[i] = true
+	}
+	if len(indices) == 0 {
+		return nil
 	}
-	if !pq.IsEmpty() {
+	for _, i := range indices {
+		x := pq.nodeAt(i)
+		pq.detach(x)
+		pq.setNode(i, nil)
+		pq.freeNode(x)
+		pq.record("delete %d", i)
+	}
+	if pq.length > 0 {
 		pq.consolidate()
 	} else {
 		pq.min = nil
 	}
-	pq.nodes[i] = nil
-	pq.length--
 	return nil
 }
 
-// greater compares two keys
-func greater(n float32, m float32) bool {
-	return n > m
-}
-
-// link links a new root key. Assuming root1 holds a greater key than root2, root2 becomes the new root
-func (pq *IndexFibonacciMinPQ) link(root1, root2 *node) {
-	root1.parent = root2
-	root2.child = pq.insertNode(root1, root2.child)
-	root2.order++
-}
-
-// cut removes a Node from its parent's child list and insert it in the root list.
-// If the parent Node already lost a child, reshapes the heap accordingly.
-func (pq *IndexFibonacciMinPQ) cut(i int) {
-	x := pq.nodes[i]
-	parent := x.parent
-	parent.child = pq.cutNode(x, parent.child)
-	x.parent = nil
-	parent.order--
-	pq.head = pq.insertNode(x, pq.head)
-	parent.mark = !parent.mark
-	if !parent.mark && parent.parent != nil {
-		pq.cut(parent.index)
-	}
-}
-
-// consolidate coalesces the roots, thus reshapes the heap.
-func (pq *IndexFibonacciMinPQ) consolidate() {
-	//TODO: Caching a map greatly improves performances
-	//TODO: Check for dangling memory references!!!
-	pq.table = make(map[int]*node)
-	x := pq.head
-	maxOrder := 0
-	pq.min = pq.head
-	var y, z *node
-	for ok := true; ok; ok = (*x != *pq.head) {
-		y = x
-		x = x.next
-		z = pq.table[y.order]
-		for z != nil {
-			delete(pq.table, y.order)
-			if greater(y.key, z.key) {
-				pq.link(y, z)
-				y = z
-			} else {
-				pq.link(z, y)
+// Consolidate links the trees of the root list until no two trees have the same order.
+// Lazy priority queues defer consolidations, see NewLazyIndexFibonacciPQ.
+// Worst case is O(n), O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) Consolidate() {
+	if pq.head == nil {
+		return
+	}
+	pq.consolidate()
+}
+
+// Split removes the key associated with index i along with the keys of its descendants
+// in the heap, and returns them in a new priority queue sharing the index range and
+// the key ordering of the priority queue.
+// Worst case is O(k) for k keys split off, O(log(n)) (amortized) if index i is the minimum.
+func (pq *IndexFibonacciPQ[K]) Split(i int) (_ *IndexFibonacciPQ[K], err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	if !pq.inRange(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.Contains(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	r, err := newIndexFibonacciPQ[K](pq.max, pq.sparse != nil)
+	if err != nil {
+		return nil, err
+	}
+	r.less = pq.less
+	r.rev = pq.rev
+	r.near = pq.near
+	r.ties = pq.ties
+	r.lazy = pq.lazy
+	r.mapped = pq.mapped
+	r.seq = pq.seq
+	if pq.counts != nil {
+		r.counts = &opCounts{}
+	}
+	x := pq.nodeAt(i)
+	if x.parent != nil {
+		pq.cut(x)
+	}
+	pq.head = pq.cutNode(x, pq.head)
+	x.mark = false
+	var move func(n *node[K])
+	move = func(n *node[K]) {
+		pq.setNode(n.index, nil)
+		r.setNode(n.index, n)
+		r.length++
+		pq.record("delete %d", n.index)
+		if n.child == nil {
+			return
+		}
+		c := n.child
+		for ok := true; ok; ok = (c != n.child) {
+			move(c)
+			c = c.next
+		}
+	}
+	move(x)
+	r.head = r.insertNode(x, nil)
+	r.min = x
+	pq.length -= r.length
+	if pq.min == x {
+		if pq.length > 0 {
+			pq.consolidate()
+		} else {
+			pq.min = nil
+		}
+	}
+	return r, nil
+}
+
+// Union moves all keys of other into the priority queue, leaving other empty.
+// The index range of the priority queue grows to cover the index range of other.
+// Both queues must use the same key ordering. A bounded priority queue rejects the union
+// if it would hold more keys than its capacity; on error neither queue is changed.
+// Worst case is O(n) for merging the index arrays, the heaps are melded in O(1).
+func (pq *IndexFibonacciPQ[K]) Union(other *IndexFibonacciPQ[K]) (err error) {
+	if other == nil || other == pq {
+		return errors.New("illegal argument")
+	}
+	if err := pq.checkCapacity(pq.length + other.length); err != nil {
+		return err
+	}
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer other.notifyMinChange(other.beginMinChange(), &err)
+	for n := range other.all() {
+		if pq.Contains(n.index) {
+			return fmt.Errorf("index %d: %w", n.index, ErrIndexPresent)
+		}
+	}
+	if !pq.mapped {
+		newMax := other.max
+		for n := range other.all() {
+			if n.index < 0 {
+				return fmt.Errorf("index %d: %w", n.index, ErrIndexOutOfRange)
+			}
+			if n.index >= newMax {
+				newMax = n.index + 1
 			}
-			z = pq.table[y.order]
 		}
-		pq.table[y.order] = y
-		if y.order > maxOrder {
-			maxOrder = y.order
+		if newMax > pq.max {
+			if err := pq.Grow(newMax); err != nil {
+				return err
+			}
 		}
 	}
-	pq.head = nil
-	for _, n := range pq.table {
-		if greater(pq.min.key, n.key) {
-			pq.min = n
+	for n := range other.all() {
+		pq.setNode(n.index, n)
+		other.setNode(n.index, nil)
+		pq.record("insert %d %v", n.index, n.key)
+	}
+	pq.head = pq.meld(pq.head, other.head)
+	if pq.min == nil || (other.min != nil && pq.after(pq.min, other.min)) {
+		pq.min = other.min
+	}
+	pq.length += other.length
+	if other.seq > pq.seq {
+		pq.seq = other.seq
+	}
+	other.head = nil
+	other.min = nil
+	other.table = nil
+	other.length = 0
+	return nil
+}
+
+// UnionKeepMin moves all keys of other into the priority queue, leaving other empty.
+// Unlike Union, an index present in both queues keeps the lower of its two keys.
+// The index range of the priority queue grows to cover the index range of other.
+// Both queues must use the same key ordering. A bounded priority queue rejects the union
+// if it would hold more keys than its capacity, leaving both queues unchanged.
+// Worst case is O(n) for merging the index arrays, plus O(k log(n)) (amortized)
+// for k shared indexes.
+func (pq *IndexFibonacciPQ[K]) UnionKeepMin(other *IndexFibonacciPQ[K]) (err error) {
+	if other == nil || other == pq {
+		return errors.New("illegal argument")
+	}
+	var shared []*node[K]
+	for n := range other.all() {
+		if pq.Contains(n.index) {
+			shared = append(shared, n)
+		}
+	}
+	if err := pq.checkCapacity(pq.length + other.length - len(shared)); err != nil {
+		return err
+	}
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	defer other.notifyMinChange(other.beginMinChange(), &err)
+	for _, n := range shared {
+		if x := pq.nodeAt(n.index); pq.greater(x.key, n.key) {
+			if err := pq.DecreaseKey(n.index, n.key); err != nil {
+				return err
+			}
+		}
+		other.detach(n)
+		other.setNode(n.index, nil)
+		other.freeNode(n)
+		other.record("delete %d", n.index)
+	}
+	if len(shared) > 0 {
+		if other.length > 0 {
+			other.consolidate()
+		} else {
+			other.min = nil
+		}
+	}
+	return pq.Union(other)
+}
+
+// MergeIndexFibonacciMinPQ moves all keys of the given priority queues of float32 keys
+// into a new priority queue, leaving the given queues empty.
+// Worst case is O(n) for merging the index arrays, the heaps are melded in O(1) each.
+func MergeIndexFibonacciMinPQ(queues ...*IndexFibonacciMinPQ) (*IndexFibonacciMinPQ, error) {
+	return MergeIndexFibonacciPQ(queues...)
+}
+
+// MergeIndexFibonacciPQ moves all keys of the given priority queues into a new priority queue,
+// leaving the given queues empty. The index range of the new queue covers the index ranges
+// of the given queues, and the new queue uses the key ordering of the first queue.
+// All queues must use the same key ordering and hold disjoint indexes;
+// on error no queue is changed.
+// Worst case is O(n) for merging the index arrays, the heaps are melded in O(1) each.
+func MergeIndexFibonacciPQ[K cmp.Ordered](queues ...*IndexFibonacciPQ[K]) (*IndexFibonacciPQ[K], error) {
+	max, mapped := 0, false
+	for n, q := range queues {
+		if q == nil || slices.Contains(queues[:n], q) {
+			return nil, errors.New("illegal argument")
+		}
+		if q.max > max {
+			max = q.max
+		}
+		mapped = mapped || q.mapped
+	}
+	pq, err := newIndexFibonacciPQ[K](max, slices.ContainsFunc(queues, func(q *IndexFibonacciPQ[K]) bool {
+		return q.sparse != nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	pq.mapped = mapped
+	if len(queues) > 0 {
+		pq.less = queues[0].less
+		pq.rev = queues[0].rev
+		pq.near = queues[0].near
+		pq.ties = queues[0].ties
+		pq.lazy = queues[0].lazy
+	}
+	for _, q := range queues {
+		for n := range q.all() {
+			if pq.nodeAt(n.index) != nil {
+				return nil, fmt.Errorf("index %d: %w", n.index, ErrIndexPresent)
+			}
+			pq.setNode(n.index, n)
+		}
+	}
+	for _, q := range queues {
+		pq.head = pq.meld(pq.head, q.head)
+		if pq.min == nil || (q.min != nil && pq.after(pq.min, q.min)) {
+			pq.min = q.min
+		}
+		pq.length += q.length
+		if q.seq > pq.seq {
+			pq.seq = q.seq
+		}
+		q.Clear()
+	}
+	return pq, nil
+}
+
+// Grow extends the index range of the priority queue to indices between 0 and given newMax-1.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) Grow(newMax int) error {
+	if newMax < pq.max {
+		return errors.New("cannot shrink the priority queue")
+	}
+	if pq.mapped {
+		return errors.New("cannot grow a priority queue of unbounded index range")
+	}
+	if pq.sparse == nil {
+		nodes := make([]*node[K], newMax)
+		copy(nodes, pq.nodes)
+		pq.nodes = nodes
+	}
+	pq.max = newMax
+	pq.record("grow %d", newMax)
+	return nil
+}
+
+// ShrinkMax reduces the index range of the priority queue to indices between 0 and given newMax-1,
+// reallocating the index array. Unlike Compact, the indexes in the priority queue are preserved,
+// so all of them must be lower than newMax.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) ShrinkMax(newMax int) error {
+	if pq.mapped {
+		return errors.New("cannot shrink a priority queue of unbounded index range")
+	}
+	if newMax < 0 || newMax > pq.max {
+		return fmt.Errorf("illegal argument: cannot shrink the index range %d to %d", pq.max, newMax)
+	}
+	for x := range pq.all() {
+		if x.index >= newMax {
+			return fmt.Errorf("index %d: %w", x.index, ErrIndexOutOfRange)
+		}
+	}
+	if pq.sparse == nil {
+		pq.nodes = slices.Clone(pq.nodes[:newMax])
+	}
+	pq.max = newMax
+	pq.record("shrink %d", newMax)
+	return nil
+}
+
+// Compact renumbers the indexes in the priority queue to indices between 0 and Len()-1,
+// preserving their order, and shrinks the index range to Len().
+// Returns the new index of every index in the priority queue.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) Compact() map[int]int {
+	defer pq.notifyMinChange(pq.beginMinChange(), nil)
+	mapping := make(map[int]int, pq.length)
+	nodes := make([]*node[K], 0, pq.length)
+	for x := range pq.all() {
+		mapping[x.index] = len(nodes)
+		nodes = append(nodes, x)
+	}
+	if !pq.mapped {
+		pq.max = pq.length
+	}
+	if pq.sparse != nil {
+		clear(pq.sparse)
+	} else {
+		pq.nodes = nodes
+	}
+	for j, x := range nodes {
+		x.index = j
+		pq.setNode(j, x)
+	}
+	pq.record("compact")
+	return mapping
+}
+
+// Equal returns true if the priority queues have the same index range and
+// associate the same keys with the same indexes, regardless of the shape of their heaps.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) Equal(other *IndexFibonacciPQ[K]) bool {
+	if other == nil || pq.max != other.max || pq.length != other.length {
+		return false
+	}
+	for n := range pq.all() {
+		m := other.nodeAt(n.index)
+		if m == nil || n.key != m.key {
+			return false
 		}
-		pq.head = pq.insertNode(n, pq.head)
 	}
+	return true
 }
 
-// insertNode inserts a Node in a circular list containing head, returns a new head.
-func (pq *IndexFibonacciMinPQ) insertNode(x, head *node) *node {
-	if head == nil {
-		x.prev = x
-		x.next = x
+// Clone returns a deep copy of the priority queue.
+// The copy shares no nodes with the priority queue and the two can be mutated independently.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) Clone() *IndexFibonacciPQ[K] {
+	c := &IndexFibonacciPQ[K]{
+		tree: tree[K]{
+			length: pq.length,
+			less:   pq.less,
+			rev:    pq.rev,
+			near:   pq.near,
+			ties:   pq.ties,
+			seq:    pq.seq,
+			lazy:   pq.lazy,
+		},
+		max:      pq.max,
+		pool:     &sync.Pool{},
+		capacity: pq.capacity,
+		mapped:   pq.mapped,
+	}
+	if pq.counts != nil {
+		c.counts = &opCounts{}
+	}
+	if pq.sparse != nil {
+		c.sparse = make(map[int]*node[K], pq.length)
 	} else {
-		head.prev.next = x
-		x.next = head
-		x.prev = head.prev
-		head.prev = x
+		c.nodes = make([]*node[K], pq.max)
+	}
+	if pq.head != nil {
+		c.head = c.cloneList(pq.head, nil)
+		c.min = c.nodeAt(pq.min.index)
+	}
+	return c
+}
+
+// WithInsert returns a copy of the priority queue that associates the given key with index i.
+// The priority queue is not modified.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) WithInsert(i int, key K) (*IndexFibonacciPQ[K], error) {
+	c := pq.Clone()
+	if err := c.Insert(i, key); err != nil {
+		return nil, err
 	}
-	return x
+	return c, nil
 }
 
-// cutNode removes a tree from the list defined by the head pointer.
-func (pq *IndexFibonacciMinPQ) cutNode(x, head *node) *node {
-	if *x.next == *x {
-		x.next = nil
-		x.prev = nil
+// WithoutMin returns a copy of the priority queue without the minimum key along with
+// the index associated with the minimum key. The priority queue is not modified.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) WithoutMin() (*IndexFibonacciPQ[K], int, error) {
+	if pq.IsEmpty() {
+		return nil, 0, ErrEmpty
+	}
+	c := pq.Clone()
+	i, err := c.DelMin()
+	if err != nil {
+		return nil, 0, err
+	}
+	return c, i, nil
+}
+
+// SetReversed reverses the order of the keys if reversed is true and restores it otherwise,
+// so that DelMin deletes the maximum key of the original order. The heap is rebuilt.
+// Returns an error if the priority queue is being iterated or drained.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) SetReversed(reversed bool) (err error) {
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	if pq.visiting > 0 {
+		return errors.New("cannot reverse the priority queue while it is iterated or drained")
+	}
+	if pq.rev == reversed {
 		return nil
 	}
-	x.next.prev = x.prev
-	x.prev.next = x.next
-	res := x.next
-	x.next = nil
-	x.prev = nil
-	if *head == *x {
-		return res
+	pq.rev = reversed
+	pq.record("reverse %t", reversed)
+	if pq.head == nil {
+		return nil
 	}
-	return head
+	pq.Rebuild()
+	pq.consolidate()
+	return nil
 }
 
-// meld merges two lists together.
-func (pq *IndexFibonacciMinPQ) meld(x, y *node) *node {
-	if x == nil {
-		return y
+// Rebuild discards the shape of the heap and rebuilds it from the index array as a single flat
+// root list, leaving the consolidation to the next deletion. The indexes in the priority queue,
+// along with their keys and values, are preserved. It recovers from corrupt sibling, parent and
+// child links and resets the amortization state, such as marks.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) Rebuild() {
+	defer pq.notifyMinChange(pq.beginMinChange(), nil)
+	pq.head = nil
+	pq.min = nil
+	pq.length = 0
+	clear(pq.table)
+	for x := range pq.all() {
+		x.parent = nil
+		x.child = nil
+		x.order = 0
+		x.mark = false
+		pq.insert(x)
+	}
+}
+
+// Clear removes all keys from the priority queue.
+// The index range is retained and the index array is reused.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) Clear() {
+	defer pq.notifyMinChange(pq.beginMinChange(), nil)
+	clear(pq.nodes)
+	clear(pq.sparse)
+	pq.head = nil
+	pq.min = nil
+	pq.table = nil
+	pq.length = 0
+	pq.record("clear")
+}
+
+// checkCapacity returns an error if a bounded priority queue cannot hold n keys.
+func (pq IndexFibonacciPQ[K]) checkCapacity(n int) error {
+	if pq.capacity > 0 && n > pq.capacity {
+		return fmt.Errorf("illegal argument: cannot hold %d keys in a priority queue of capacity %d", n, pq.capacity)
+	}
+	return nil
+}
+
+// makeRoom deletes the maximum key of a full bounded priority queue if it is greater
+// than the given key. Returns false if the queue is full and the key is not inserted.
+func (pq *IndexFibonacciPQ[K]) makeRoom(key K) bool {
+	if pq.capacity == 0 || pq.length < pq.capacity {
+		return true
+	}
+	max := pq.maxNode()
+	if !pq.greater(max.key, key) {
+		return false
+	}
+	return pq.Delete(max.index) == nil
+}
+
+// maxNode returns the Node holding the maximum key of a non-empty priority queue.
+func (pq IndexFibonacciPQ[K]) maxNode() *node[K] {
+	var max *node[K]
+	for x := range pq.all() {
+		if max == nil || pq.after(x, max) {
+			max = x
+		}
+	}
+	return max
+}
+
+// nodeAt returns the Node associated with index i, or nil if i is not on the priority queue.
+func (pq IndexFibonacciPQ[K]) nodeAt(i int) *node[K] {
+	if pq.sparse != nil {
+		return pq.sparse[i]
+	}
+	return pq.nodes[i]
+}
+
+// setNode associates a Node with index i, a nil Node removes the association.
+func (pq *IndexFibonacciPQ[K]) setNode(i int, x *node[K]) {
+	switch {
+	case pq.sparse == nil:
+		pq.nodes[i] = x
+	case x == nil:
+		delete(pq.sparse, i)
+	default:
+		pq.sparse[i] = x
+	}
+}
+
+// all returns an iterator over the Nodes of the priority queue in ascending order of indexes.
+func (pq IndexFibonacciPQ[K]) all() iter.Seq[*node[K]] {
+	return func(yield func(*node[K]) bool) {
+		if pq.sparse != nil {
+			for _, i := range slices.Sorted(maps.Keys(pq.sparse)) {
+				if !yield(pq.sparse[i]) {
+					return
+				}
+			}
+			return
+		}
+		for _, x := range pq.nodes {
+			if x != nil && !yield(x) {
+				return
+			}
+		}
+	}
+}
+
+// newNode returns a Node holding the given index and key, reusing a freed Node if available.
+func (pq *IndexFibonacciPQ[K]) newNode(i int, key K) *node[K] {
+	if pq.pool != nil {
+		if x, ok := pq.pool.Get().(*node[K]); ok {
+			x.key = key
+			x.index = i
+			x.seq = pq.nextSeq()
+			return x
+		}
+	}
+	return &node[K]{
+		key:   key,
+		index: i,
+		seq:   pq.nextSeq(),
+	}
+}
+
+// nextSeq returns the sequence number of the next inserted Node.
+func (pq *IndexFibonacciPQ[K]) nextSeq() uint64 {
+	pq.seq++
+	return pq.seq
+}
+
+// freeNode resets a Node removed from the heap and makes it available for reuse.
+func (pq *IndexFibonacciPQ[K]) freeNode(x *node[K]) {
+	if pq.pool == nil {
+		return
+	}
+	*x = node[K]{}
+	pq.pool.Put(x)
+}
+
+// recoverCorrupt recovers from a nil pointer dereference caused by the broken links
+// of a corrupted heap and sets the error to ErrCorruptHeap. Other panics are propagated,
+// so that bugs still surface.
+func recoverCorrupt(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	re, ok := r.(runtime.Error)
+	if !ok || !strings.Contains(re.Error(), "nil pointer dereference") {
+		panic(r)
 	}
-	if y == nil {
-		return x
+	*err = fmt.Errorf("%w: %v", ErrCorruptHeap, re)
+}
+
+// isNaN returns true if the key is a floating point NaN.
+func isNaN[K cmp.Ordered](key K) bool {
+	return key != key
+}
+
+// cloneList copies the circular list defined by the head pointer along with the subtrees
+// of its Nodes, returns the head of the copy.
+func (pq *IndexFibonacciPQ[K]) cloneList(head, parent *node[K]) *node[K] {
+	var res *node[K]
+	x := head
+	for ok := true; ok; ok = (x != head) {
+		y := &node[K]{
+			key:    x.key,
+			order:  x.order,
+			index:  x.index,
+			seq:    x.seq,
+			value:  x.value,
+			parent: parent,
+			mark:   x.mark,
+		}
+		pq.setNode(y.index, y)
+		if x.child != nil {
+			y.child = pq.cloneList(x.child, y)
+		}
+		if res == nil {
+			res = pq.insertNode(y, nil)
+		} else {
+			pq.insertNode(y, res)
+		}
+		x = x.next
 	}
-	x.prev.next = y.next
-	y.next.prev = x.prev
-	x.prev = y
-	y.next = x
-	return x
+	return res
 }
 
-// Slice returns a slice over the indexes in the priority queue in ascending order.
-// Returns an empty slice on error.
+// ForEach calls fn with every index in the priority queue along with its key,
+// until fn returns false. The order of the traversal is unspecified.
+// fn must not modify the priority queue.
 // Worst case is O(n).
-func (pq IndexFibonacciMinPQ) Slice() []int {
-	result := make([]int, 0, pq.max)
-	for _, n := range pq.nodes {
-		if n != nil {
-			result = append(result, n.index)
+func (pq *IndexFibonacciPQ[K]) ForEach(fn func(index int, key K) bool) {
+	pq.visiting++
+	defer func() { pq.visiting-- }()
+	for x := range pq.all() {
+		if !fn(x.index, x.key) {
+			return
 		}
 	}
+}
+
+// KeyVector returns a slice of length Cap() holding the key associated with every index
+// in the priority queue and the given absent key at every other index.
+// Returns nil if the index range is unbounded.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) KeyVector(absent K) []K {
+	if pq.mapped {
+		return nil
+	}
+	result := make([]K, pq.max)
+	for i := range result {
+		result[i] = absent
+	}
+	for x := range pq.all() {
+		result[x.index] = x.key
+	}
+	return result
+}
+
+// SortedPairs returns the indexes in the priority queue along with their keys in ascending
+// order of keys. The priority queue is not modified.
+// Worst case is O(n log(n)).
+func (pq IndexFibonacciPQ[K]) SortedPairs() []Pair[K] {
+	result := make([]Pair[K], 0, pq.length)
+	c := pq.Clone()
+	for !c.IsEmpty() {
+		i, key, err := c.DelMinWithKey()
+		if err != nil {
+			break
+		}
+		result = append(result, Pair[K]{Index: i, Key: key})
+	}
+	return result
+}
+
+// Snapshot returns the indexes in the priority queue along with their keys in ascending
+// order of keys. Equal keys are in ascending order of indexes, thus the result does not
+// depend on the order of insertion. The priority queue is not modified.
+// Worst case is O(n log(n)).
+func (pq IndexFibonacciPQ[K]) Snapshot() []Pair[K] {
+	result := make([]Pair[K], 0, pq.length)
+	pq.ForEach(func(i int, key K) bool {
+		result = append(result, Pair[K]{Index: i, Key: key})
+		return true
+	})
+	slices.SortFunc(result, func(a, b Pair[K]) int {
+		if pq.greater(a.Key, b.Key) {
+			return 1
+		}
+		if pq.greater(b.Key, a.Key) {
+			return -1
+		}
+		return cmp.Compare(a.Index, b.Index)
+	})
+	return result
+}
+
+// TopK returns the indexes associated with the k minimum keys in ascending order of keys.
+// If k exceeds the length of the priority queue, all indexes are returned.
+// The priority queue is not modified.
+// Worst case is O(n + k log(n)).
+func (pq *IndexFibonacciPQ[K]) TopK(k int) ([]int, error) {
+	if k < 0 {
+		return nil, errors.New("cannot return a negative number of keys")
+	}
+	if k > pq.length {
+		k = pq.length
+	}
+	result := make([]int, 0, k)
+	c := pq.Clone()
+	for len(result) < k {
+		i, err := c.DelMin()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+// KthMin returns the index associated with the k-th minimum key and the k-th minimum key,
+// counting from 1. The priority queue is not modified.
+// Worst case is O(n + k log(n)).
+func (pq IndexFibonacciPQ[K]) KthMin(k int) (int, K, error) {
+	var zero K
+	if k < 1 || k > pq.length {
+		return 0, zero, fmt.Errorf("k %d: %w", k, ErrIndexOutOfRange)
+	}
+	c := pq.Clone()
+	for ; k > 1; k-- {
+		if _, err := c.DelMin(); err != nil {
+			return 0, zero, err
+		}
+	}
+	return c.min.index, c.min.key, nil
+}
+
+// Indices returns the indexes in the priority queue in ascending order of indexes,
+// regardless of their keys. See Slice for the indexes in ascending order of keys.
+// Worst case is O(n), or O(n log(n)) for a sparse priority queue.
+func (pq IndexFibonacciPQ[K]) Indices() []int {
+	result := make([]int, 0, pq.length)
+	for x := range pq.all() {
+		result = append(result, x.index)
+	}
+	return result
+}
+
+// Slice returns a slice over the indexes in the priority queue in ascending order of keys.
+// Equal keys are in ascending order of indexes. The priority queue is not modified.
+// Worst case is O(n log(n)).
+func (pq IndexFibonacciPQ[K]) Slice() []int {
+	pairs := pq.Snapshot()
+	result := make([]int, 0, len(pairs))
+	for _, p := range pairs {
+		result = append(result, p.Index)
+	}
 	return result
 }
@@ -0,0 +1,99 @@
+package heap
+
+import "testing"
+
+func TestInsert64(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ64(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pq.Insert(2, 1)
+	if err != nil {
+		t.Fatalf("insert returned unexpected error: %v", err)
+	}
+	err = pq.Insert(1, 2)
+	if err != nil {
+		t.Fatalf("insert returned unexpected error: %v", err)
+	}
+	if pq.Len() != 2 {
+		t.Fatalf("expected pq length 2, but got %d", pq.Len())
+	}
+	if pq.IsEmpty() {
+		t.Fatal("expected non empty queue")
+	}
+}
+
+func TestInsertAndDelMin64(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ64(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const base, eps = 6371.0088, 1e-9
+	testData := []struct {
+		i int
+		k float64
+	}{
+		{1, base + 1*eps},
+		{4, base + 4*eps},
+		{9, base + 9*eps},
+		{2, base + 2*eps},
+		{3, base + 3*eps},
+		{5, base + 5*eps},
+		{7, base + 7*eps},
+		{8, base + 8*eps},
+		{6, base + 6*eps},
+	}
+	for _, testCase := range testData {
+		if float32(testCase.k) != float32(base) {
+			t.Fatalf("expected keys indistinguishable as float32")
+		}
+		if err := pq.Insert(testCase.i, testCase.k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pq.Len() != 9 {
+		t.Fatalf("expected pq length 9, but got %d", pq.Len())
+	}
+
+	expectedDel := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	for n := 0; !pq.IsEmpty(); n++ {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatalf("delete minimun failed: %v", err)
+		}
+		if expectedDel[n] != i {
+			t.Fatalf("expected %d, but got %d", expectedDel[n], i)
+		}
+	}
+}
+
+func TestDecreaseKey64(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ64(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const base, eps = 6371.0088, 1e-9
+	for i := 1; i < 10; i++ {
+		if err = pq.Insert(i, base+float64(i)*eps); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 9; i > 1; i-- {
+		if err = pq.DecreaseKey(i, base-float64(i)*eps); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+	for i := 9; i > 1; i-- {
+		index, err := pq.DelMin()
+		if err != nil {
+			t.Fatalf("delete minumum returned an error: %v", err)
+		}
+		if index != i {
+			t.Fatalf("expected index %d, but got %d", i, index)
+		}
+	}
+}
@@ -1,6 +1,16 @@
 package heap
 
-import "testing"
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestInsert(t *testing.T) {
 	pq, err := NewIndexFibonacciMinPQ(10)
@@ -101,3 +111,3036 @@ func TestDecreaseKey(t *testing.T) {
 		}
 	}
 }
+
+// checkHeap verifies the structure of the heap.
+func checkHeap[K cmp.Ordered](t *testing.T, pq *IndexFibonacciPQ[K]) {
+	t.Helper()
+	if err := pq.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEqualKeys(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, 0.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if err := pq.Delete(7); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	n := 0
+	for !pq.IsEmpty() {
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatalf("delete minimun failed: %v", err)
+		}
+		checkHeap(t, pq)
+		n++
+	}
+	if n != 8 {
+		t.Fatalf("expected 8 deleted keys, but got %d", n)
+	}
+}
+
+func TestCascadingCut(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 17; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.head.next != pq.head || pq.head.order != 4 {
+		t.Fatalf("expected a single tree of order 4, but got %v", pq.head)
+	}
+
+	var p *node[float32]
+	for _, n := range pq.nodes {
+		if n != nil && n.order == 2 && n.parent != nil && n.parent.parent != nil {
+			p = n
+		}
+	}
+	if p == nil {
+		t.Fatal("expected a non-root node of order 2 with a non-root parent")
+	}
+	q := p.parent
+	first, second := p.child.index, p.child.next.index
+
+	if err := pq.DecreaseKey(first, -1); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !p.mark || p.parent != q {
+		t.Fatalf("expected %v to be marked and not cut", p)
+	}
+	if err := pq.DecreaseKey(second, -2); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if p.mark || p.parent != nil {
+		t.Fatalf("expected %v to be an unmarked root", p)
+	}
+	if !q.mark {
+		t.Fatalf("expected %v to be marked", q)
+	}
+	if err := pq.DecreaseKey(q.child.index, -3); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if q.parent != nil {
+		t.Fatalf("expected %v to be cut", q)
+	}
+
+	var prev float32 = -4
+	for !pq.IsEmpty() {
+		key, err := pq.MinKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev > key {
+			t.Fatalf("expected key greater than %.1f, but got %.1f", prev, key)
+		}
+		prev = key
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.Peek(); err == nil {
+		t.Fatal("expected error on empty queue")
+	}
+	if err := pq.Insert(3, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(5, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	i, key, err := pq.Peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 5 || key != 0.1 {
+		t.Fatalf("expected 5 and 0.1, but got %d and %.1f", i, key)
+	}
+}
+
+func TestGenericKeys(t *testing.T) {
+	ipq, err := NewIndexFibonacciPQ[int64](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []int64{1 << 62, 3, 1<<62 + 1, -7}
+	for i, k := range keys {
+		if err := ipq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectedDel := []int{3, 1, 0, 2}
+	for _, expected := range expectedDel {
+		i, err := ipq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+
+	spq, err := NewIndexFibonacciPQ[string](3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, k := range []string{"b", "c", "a"} {
+		if err := spq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if key, err := spq.MinKey(); err != nil || key != "a" {
+		t.Fatalf("expected minimum key a, but got %q (%v)", key, err)
+	}
+}
+
+func TestComparator(t *testing.T) {
+	pq, err := NewIndexFibonacciPQWithComparator(10, func(a, b float32) bool {
+		return a > b
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.DecreaseKey(3, 0.1); err == nil {
+		t.Fatal("expected error when moving key away from the front")
+	}
+	if err := pq.DecreaseKey(3, 0.95); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.IncreaseKey(9, 0.95); err == nil {
+		t.Fatal("expected error when moving key towards the front")
+	}
+	if err := pq.IncreaseKey(9, 0.05); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := []int{3, 8, 7, 6, 5, 4, 2, 1, 9, 0}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQ(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := pq.Insert(i, float32(i)*2); err != nil {
+			t.Fatal(err)
+		}
+		if err := other.Insert(i+4, float32(i)*2+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Union(other); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Len() != 7 {
+		t.Fatalf("expected pq length 7, but got %d", pq.Len())
+	}
+	if !other.IsEmpty() || other.Contains(4) {
+		t.Fatal("expected other queue to be empty")
+	}
+	expectedDel := []int{4, 1, 5, 2, 6, 3, 7}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestUnionKeepMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQ(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pqKeys := map[int]float32{0: 0.5, 1: 0.1, 2: 0.4, 3: 0.3, 5: 0.9}
+	otherKeys := map[int]float32{1: 0.2, 2: 0.05, 3: 0.3, 4: 0.6, 5: 0.7, 7: 0.8}
+	for i, k := range pqKeys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, k := range otherKeys {
+		if err := other.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := other.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Insert(2, 0.05); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.UnionKeepMin(pq); err == nil {
+		t.Fatal("expected error on union with itself")
+	}
+	if err := pq.UnionKeepMin(other); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	checkHeap(t, other)
+	if !other.IsEmpty() || other.Contains(7) {
+		t.Fatal("expected other queue to be empty")
+	}
+	if pq.Cap() != 8 || pq.Len() != 7 {
+		t.Fatalf("expected 7 keys within 8 indexes, but got %d within %d", pq.Len(), pq.Cap())
+	}
+	expected := []Pair[float32]{{2, 0.05}, {1, 0.1}, {3, 0.3}, {0, 0.5}, {4, 0.6}, {5, 0.7}, {7, 0.8}}
+	for _, p := range expected {
+		i, key, err := pq.DelMinWithKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if i != p.Index || key != p.Key {
+			t.Fatalf("expected %d with key %f, but got %d with key %f", p.Index, p.Key, i, key)
+		}
+	}
+}
+
+func TestUnionSharedIndex(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQ(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(1, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Insert(1, 0.2); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Union(other); err == nil {
+		t.Fatal("expected error on shared index")
+	}
+	if pq.Len() != 1 || other.Len() != 1 {
+		t.Fatal("expected queues to be unchanged")
+	}
+}
+
+func TestClone(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(10-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	c := pq.Clone()
+	checkHeap(t, c)
+	if _, err := c.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DecreaseKey(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Len() != 9 {
+		t.Fatalf("expected pq length 9, but got %d", pq.Len())
+	}
+	if i, err := pq.MinIndex(); err != nil || i != 8 {
+		t.Fatalf("expected minimum index 8, but got %d (%v)", i, err)
+	}
+	if key, err := pq.KeyOf(0); err != nil || key != 10 {
+		t.Fatalf("expected key 10, but got %.1f (%v)", key, err)
+	}
+}
+
+func TestClear(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	pq.Clear()
+	if !pq.IsEmpty() || pq.Contains(3) {
+		t.Fatal("expected empty queue")
+	}
+	if _, err := pq.DelMin(); err == nil {
+		t.Fatal("expected error on empty queue")
+	}
+	if err := pq.Insert(3, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.DelMin(); err != nil || i != 3 {
+		t.Fatalf("expected 3, but got %d (%v)", i, err)
+	}
+}
+
+func TestSortedPairs(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4, 0.0}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pairs := pq.SortedPairs()
+	if pq.Len() != 10 {
+		t.Fatalf("expected pq length 10, but got %d", pq.Len())
+	}
+	for _, p := range pairs {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Index != i || p.Key != keys[i] {
+			t.Fatalf("expected %d with key %.1f, but got %d with key %.1f", i, keys[i], p.Index, p.Key)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected all keys in sorted pairs")
+	}
+}
+
+func TestSlice(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.3, 0.1, 0.4, 0.0, 0.2}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := pq.Slice()
+	expected := []int{3, 1, 4, 0, 2}
+	if len(s) != len(expected) {
+		t.Fatalf("expected %v, but got %v", expected, s)
+	}
+	for n := range expected {
+		if expected[n] != s[n] {
+			t.Fatalf("expected %v, but got %v", expected, s)
+		}
+	}
+	if pq.Len() != len(keys) {
+		t.Fatalf("expected the priority queue to be unmodified, but got %v", pq)
+	}
+	pq.Clear()
+	// Equal keys inserted in descending order of indexes are returned in ascending order of indexes.
+	for _, i := range []int{4, 2, 0, 3, 1} {
+		if err := pq.Insert(i, float32(i%2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if expected := []int{0, 2, 4, 1, 3}; !slices.Equal(pq.Slice(), expected) {
+		t.Fatalf("expected %v, but got %v", expected, pq.Slice())
+	}
+}
+
+func BenchmarkClear(b *testing.B) {
+	pq, err := NewIndexFibonacciMinPQ(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		pq.Clear()
+		for i := 0; i < 1000; i++ {
+			pq.Insert(i, float32(i))
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		pq, err := NewIndexFibonacciMinPQ(1000)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := 0; i < 1000; i++ {
+			pq.Insert(i, float32(i))
+		}
+	}
+}
+
+func TestNewFromMap(t *testing.T) {
+	keys := map[int]float32{
+		0: 0.5,
+		3: 0.2,
+		5: 0.9,
+		7: 0.1,
+		9: 0.4,
+	}
+	pq, err := NewIndexFibonacciMinPQFromMap(10, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Len() != 5 {
+		t.Fatalf("expected pq length 5, but got %d", pq.Len())
+	}
+	if err := pq.Insert(1, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := []int{7, 3, 1, 9, 0, 5}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestNewFromMapOutOfRange(t *testing.T) {
+	if _, err := NewIndexFibonacciMinPQFromMap(3, map[int]float32{1: 0.1, 3: 0.3}); err == nil {
+		t.Fatal("expected error on out of range index")
+	}
+	if _, err := NewIndexFibonacciMinPQFromMap(3, map[int]float32{-1: 0.1}); err == nil {
+		t.Fatal("expected error on negative index")
+	}
+}
+
+func TestZeroMax(t *testing.T) {
+	for _, sparse := range []bool{false, true} {
+		pq, err := newIndexFibonacciPQ[float32](0, sparse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range []int{-1, 0, 1} {
+			if err := pq.Insert(i, 0.5); !errors.Is(err, ErrIndexOutOfRange) {
+				t.Fatalf("expected %v for index %d, but got %v", ErrIndexOutOfRange, i, err)
+			}
+			if pq.Contains(i) {
+				t.Fatalf("expected %d not to be contained", i)
+			}
+			if _, err := pq.KeyOf(i); err == nil {
+				t.Fatalf("expected error on key of %d", i)
+			}
+			if err := pq.DecreaseKey(i, 0); err == nil {
+				t.Fatalf("expected error on decreasing key of %d", i)
+			}
+			if err := pq.Delete(i); err == nil {
+				t.Fatalf("expected error on deleting %d", i)
+			}
+		}
+		if err := pq.InsertAll([]Pair[float32]{{Index: 0, Key: 0.5}}); err == nil {
+			t.Fatal("expected error on inserting pairs")
+		}
+		if !pq.IsEmpty() || pq.Len() != 0 || pq.Cap() != 0 {
+			t.Fatalf("expected an empty queue of no indexes, but got %v", pq)
+		}
+		if s := pq.Slice(); len(s) != 0 {
+			t.Fatalf("expected empty slice, but got %v", s)
+		}
+		if _, err := pq.DelMin(); !errors.Is(err, ErrEmpty) {
+			t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+		}
+		if _, err := pq.MinKey(); !errors.Is(err, ErrEmpty) {
+			t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+		}
+		if _, _, err := pq.ReplaceMin(0, 0.5); !errors.Is(err, ErrEmpty) {
+			t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+		}
+		if v := pq.KeyVector(-1); len(v) != 0 {
+			t.Fatalf("expected empty key vector, but got %v", v)
+		}
+		if s := pq.Snapshot(); len(s) != 0 {
+			t.Fatalf("expected empty snapshot, but got %v", s)
+		}
+		if indices := pq.DrainSorted(); len(indices) != 0 {
+			t.Fatalf("expected no index drained, but got %v", indices)
+		}
+		if m := pq.Compact(); len(m) != 0 {
+			t.Fatalf("expected no index compacted, but got %v", m)
+		}
+		if err := pq.ShrinkMax(0); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		pq.Consolidate()
+		pq.Rebuild()
+		pq.Clear()
+		_ = pq.String()
+		_ = pq.ToDOT()
+		_ = pq.Stats()
+		if _, err := pq.MarshalJSON(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pq.WriteTo(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.Grow(1); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.Insert(0, 0.5); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+}
+
+func TestGrow(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := pq.Insert(i, float32(i)*2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(5, 0.5); err == nil {
+		t.Fatal("expected error on out of range index")
+	}
+	if err := pq.Grow(4); err == nil {
+		t.Fatal("expected error when shrinking")
+	}
+	if err := pq.Grow(10); err != nil {
+		t.Fatal(err)
+	}
+	for i := 5; i < 10; i++ {
+		if err := pq.Insert(i, float32(i-5)*2+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectedDel := []int{0, 5, 1, 6, 2, 7, 3, 8, 4, 9}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestShrinkMax(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	pq.SetRecorder(&log)
+	for i := 0; i < 1000; i++ {
+		if err := pq.Insert(i, float32(i%10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 8; i < 1000; i++ {
+		if err := pq.Delete(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(42, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ShrinkMax(10); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	if pq.Cap() != 1000 || pq.Len() != 9 {
+		t.Fatalf("expected unchanged queue, but got %v", pq)
+	}
+	if err := pq.ShrinkMax(1001); err == nil {
+		t.Fatal("expected error when growing")
+	}
+	if err := pq.Delete(42); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ShrinkMax(8); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Cap() != 8 || len(pq.nodes) != 8 || pq.Len() != 8 {
+		t.Fatalf("expected 8 keys within 8 indexes, but got %v", pq)
+	}
+	if err := pq.Insert(8, 0.5); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	r, err := ReplayIndexFibonacciMinPQ(1000, strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.Equal(r) {
+		t.Fatalf("expected %v, but got %v", pq, r)
+	}
+	for expected := 0; expected < 8; expected++ {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestNaNKey(t *testing.T) {
+	nan := float32(math.NaN())
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(0, nan); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if pq.Contains(0) || !pq.IsEmpty() {
+		t.Fatal("expected NaN key not to be inserted")
+	}
+	if err := pq.Insert(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ChangeKey(1, nan); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if err := pq.DecreaseKey(1, nan); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if err := pq.IncreaseKey(1, nan); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if _, err := NewIndexFibonacciMinPQFromMap(2, map[int]float32{1: nan}); !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %v", ErrNaNKey, err)
+	}
+	if key, err := pq.KeyOf(1); err != nil || key != 0.5 {
+		t.Fatalf("expected key 0.5, but got %.1f (%v)", key, err)
+	}
+}
+
+func TestInfKey(t *testing.T) {
+	inf := float32(math.Inf(1))
+	pq, err := NewIndexFibonacciMinPQ(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(0, inf); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(2, -inf); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := []int{2, 1, 0}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestTopK(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testData := []struct {
+		k        int
+		expected []int
+	}{
+		{0, []int{}},
+		{2, []int{2, 4}},
+		{5, []int{2, 4, 0, 3, 1}},
+		{8, []int{2, 4, 0, 3, 1}},
+	}
+	for _, testCase := range testData {
+		top, err := pq.TopK(testCase.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(top) != len(testCase.expected) {
+			t.Fatalf("expected %v, but got %v", testCase.expected, top)
+		}
+		for n := range top {
+			if top[n] != testCase.expected[n] {
+				t.Fatalf("expected %v, but got %v", testCase.expected, top)
+			}
+		}
+	}
+	if pq.Len() != 5 {
+		t.Fatalf("expected pq length 5, but got %d", pq.Len())
+	}
+	if _, err := pq.TopK(-1); err == nil {
+		t.Fatal("expected error on negative k")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 9; i > 0; i-- {
+		if err := other.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pq.Stats() == other.Stats() {
+		t.Fatal("expected heaps of different shape")
+	}
+	if !pq.Equal(other) || !other.Equal(pq) {
+		t.Fatal("expected queues to be equal")
+	}
+	if err := other.DecreaseKey(5, 4.5); err != nil {
+		t.Fatal(err)
+	}
+	if pq.Equal(other) {
+		t.Fatal("expected queues with different keys not to be equal")
+	}
+	if err := other.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	if pq.Equal(other) {
+		t.Fatal("expected queues with different indexes not to be equal")
+	}
+	grown := pq.Clone()
+	if err := grown.Grow(11); err != nil {
+		t.Fatal(err)
+	}
+	if pq.Equal(grown) || pq.Equal(nil) {
+		t.Fatal("expected queues with different index ranges not to be equal")
+	}
+}
+
+func TestDecreaseKeys(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	invalid := []map[int]float32{
+		{3: 2.5, 4: 4.5},
+		{3: 2.5, 0: -1},
+		{3: 2.5, 10: -1},
+	}
+	for _, updates := range invalid {
+		if err := pq.DecreaseKeys(updates); err == nil {
+			t.Fatalf("expected error on %v", updates)
+		}
+		if key, _ := pq.KeyOf(3); key != 3 {
+			t.Fatalf("expected key 3 to be unchanged, but got %.1f", key)
+		}
+	}
+	if err := pq.DecreaseKeys(map[int]float32{9: 0.5, 7: 1.5, 8: 8, 5: -1}); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	expectedDel := []int{5, 9, 1, 7, 2, 3, 4, 6, 8}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		i int
+		k float32
+	}{
+		{0, 0.5},
+		{1, 0.4},
+		{2, 0.3},
+		{0, 0.1},
+		{3, 0.2},
+		{1, 0.9},
+		{4, 0.6},
+		{2, 0.3},
+	}
+	for _, testCase := range testData {
+		if err := pq.Set(testCase.i, testCase.k); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+	if err := pq.Set(5, 0.1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	if pq.Len() != 5 {
+		t.Fatalf("expected pq length 5, but got %d", pq.Len())
+	}
+	expectedDel := []int{0, 3, 2, 4, 1}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestRelax(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		i       int
+		k       float32
+		changed bool
+		key     float32
+	}{
+		{0, 0.5, true, 0.5},  // inserted
+		{1, 0.4, true, 0.4},  // inserted
+		{0, 0.2, true, 0.2},  // decreased
+		{0, 0.2, false, 0.2}, // equal
+		{1, 0.9, false, 0.4}, // not increased
+		{1, 0.1, true, 0.1},  // decreased
+	}
+	for _, tc := range testData {
+		changed, err := pq.Relax(tc.i, tc.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != tc.changed {
+			t.Fatalf("expected changed %t relaxing %d to %f, but got %t", tc.changed, tc.i, tc.k, changed)
+		}
+		if key, err := pq.KeyOf(tc.i); err != nil || key != tc.key {
+			t.Fatalf("expected key %f for %d, but got %f (%v)", tc.key, tc.i, key, err)
+		}
+		checkHeap(t, pq)
+	}
+	if changed, err := pq.Relax(5, 0.1); changed || !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %t (%v)", ErrIndexOutOfRange, changed, err)
+	}
+	if changed, err := pq.Relax(2, float32(math.NaN())); changed || !errors.Is(err, ErrNaNKey) {
+		t.Fatalf("expected %v, but got %t (%v)", ErrNaNKey, changed, err)
+	}
+	if i, err := pq.MinIndex(); err != nil || i != 1 {
+		t.Fatalf("expected minimum 1, but got %d (%v)", i, err)
+	}
+}
+func TestIncreaseKeyRandom(t *testing.T) {
+	const n = 200
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]float32, n)
+	for i := range keys {
+		keys[i] = r.Float32()
+		if err := pq.Insert(i, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for round := 0; round < 20; round++ {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = -1
+		for m := 0; m < 20; m++ {
+			j := r.Intn(n)
+			if !pq.Contains(j) {
+				continue
+			}
+			keys[j] += r.Float32()
+			if err := pq.IncreaseKey(j, keys[j]); err != nil {
+				t.Fatal(err)
+			}
+			checkHeap(t, pq)
+		}
+	}
+	prev := float32(-1)
+	for !pq.IsEmpty() {
+		key, err := pq.MinKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != keys[i] || key < prev {
+			t.Fatalf("expected key %f of %d to be at least %f", key, i, prev)
+		}
+		prev = key
+	}
+}
+
+// increaseKeyByReinsert is the former implementation of IncreaseKey.
+func increaseKeyByReinsert(pq *IndexFibonacciMinPQ, i int, key float32) error {
+	if err := pq.Delete(i); err != nil {
+		return err
+	}
+	return pq.Insert(i, key)
+}
+
+func benchmarkIncreaseKey(b *testing.B, increase func(pq *IndexFibonacciMinPQ, i int, key float32) error) {
+	const n = 1000
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		pq.Insert(i, r.Float32())
+	}
+	pq.DelMin()
+	b.ResetTimer()
+	for m := 0; m < b.N; m++ {
+		i := r.Intn(n)
+		if !pq.Contains(i) {
+			continue
+		}
+		key, _ := pq.KeyOf(i)
+		if err := increase(pq, i, key+r.Float32()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIncreaseKey(b *testing.B) {
+	benchmarkIncreaseKey(b, (*IndexFibonacciMinPQ).IncreaseKey)
+}
+
+func BenchmarkIncreaseKeyByReinsert(b *testing.B) {
+	benchmarkIncreaseKey(b, increaseKeyByReinsert)
+}
+
+func TestMinInfo(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := pq.MinInfo(); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, order, err := pq.MinInfo(); err != nil || order != 0 {
+		t.Fatalf("expected order 0 before consolidation, but got %d (%v)", order, err)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	// Consolidating nine roots leaves trees of order 3 and 0,
+	// the minimum is the root of the tree of order 0.
+	i, key, order, err := pq.MinInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 1 || key != 1 || order != 0 {
+		t.Fatalf("expected index 1, key 1.0 and order 0, but got %d, %.1f and %d", i, key, order)
+	}
+	if pq.Len() != 9 {
+		t.Fatalf("expected pq length 9, but got %d", pq.Len())
+	}
+}
+
+func TestForEach(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	var sum float32
+	n := 0
+	pq.ForEach(func(i int, key float32) bool {
+		if key != float32(i) {
+			t.Fatalf("expected key %d of %d, but got %.1f", i, i, key)
+		}
+		sum += key
+		n++
+		return true
+	})
+	if n != 9 || sum != 45 {
+		t.Fatalf("expected 9 keys summing to 45, but got %d keys summing to %.1f", n, sum)
+	}
+}
+
+func TestForEachStop(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := 0
+	pq.ForEach(func(i int, key float32) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Fatalf("expected 3 calls, but got %d", n)
+	}
+}
+
+func TestCap(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pq.Cap() != 10 {
+		t.Fatalf("expected pq capacity 10, but got %d", pq.Cap())
+	}
+	for i := 0; i < 5; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if pq.Cap() != 10 {
+		t.Fatalf("expected pq capacity 10, but got %d", pq.Cap())
+	}
+	if err := pq.Grow(12); err != nil {
+		t.Fatal(err)
+	}
+	if pq.Cap() != 12 {
+		t.Fatalf("expected pq capacity 12, but got %d", pq.Cap())
+	}
+}
+
+func TestDelMinWithKey(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.DelMinWithKey(); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prev := float32(0)
+	for !pq.IsEmpty() {
+		i, key, err := pq.DelMinWithKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != keys[i] || key < prev {
+			t.Fatalf("expected key %.1f of %d to be at least %.1f", key, i, prev)
+		}
+		if pq.Contains(i) {
+			t.Fatalf("expected %d to be deleted", i)
+		}
+		prev = key
+	}
+}
+
+func TestInsertAll(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(9, 0.9); err != nil {
+		t.Fatal(err)
+	}
+	invalid := []struct {
+		pairs    []Pair[float32]
+		expected error
+	}{
+		{[]Pair[float32]{{0, 0.5}, {1, 0.4}, {0, 0.3}, {2, 0.2}}, ErrIndexPresent},
+		{[]Pair[float32]{{0, 0.5}, {9, 0.4}}, ErrIndexPresent},
+		{[]Pair[float32]{{0, 0.5}, {10, 0.4}}, ErrIndexOutOfRange},
+		{[]Pair[float32]{{0, 0.5}, {1, float32(math.NaN())}}, ErrNaNKey},
+	}
+	for _, testCase := range invalid {
+		if err := pq.InsertAll(testCase.pairs); !errors.Is(err, testCase.expected) {
+			t.Fatalf("expected %v, but got %v", testCase.expected, err)
+		}
+		if pq.Len() != 1 || pq.Contains(0) || pq.Contains(1) {
+			t.Fatalf("expected no key inserted from %v", testCase.pairs)
+		}
+	}
+	if err := pq.InsertAll([]Pair[float32]{{0, 0.5}, {1, 0.4}, {2, 0.3}}); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	expectedDel := []int{2, 1, 0, 9}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestInsertColumns(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := []struct {
+		indices  []int
+		keys     []float32
+		expected error
+	}{
+		{[]int{0, 1, 2}, []float32{0.5, 0.4}, ErrLengthMismatch},
+		{[]int{0}, nil, ErrLengthMismatch},
+		{[]int{0, 1, 0}, []float32{0.5, 0.4, 0.3}, ErrIndexPresent},
+		{[]int{0, 10}, []float32{0.5, 0.4}, ErrIndexOutOfRange},
+	}
+	for _, testCase := range invalid {
+		if err := pq.InsertColumns(testCase.indices, testCase.keys); !errors.Is(err, testCase.expected) {
+			t.Fatalf("expected %v, but got %v", testCase.expected, err)
+		}
+		if !pq.IsEmpty() {
+			t.Fatalf("expected no key inserted from %v and %v", testCase.indices, testCase.keys)
+		}
+	}
+	if err := pq.InsertColumns([]int{7, 3, 5, 1}, []float32{0.2, 0.8, 0.1, 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	expectedDel := []int{5, 7, 1, 3}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.2, 0.5, 0.1, 0.2, 0.5}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if err := other.Insert(i, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []Pair[float32]{{3, 0.1}, {1, 0.2}, {4, 0.2}, {0, 0.5}, {2, 0.5}, {5, 0.5}}
+	for _, q := range []*IndexFibonacciMinPQ{pq, other} {
+		snapshot := q.Snapshot()
+		if !slices.Equal(snapshot, expected) {
+			t.Fatalf("expected %v, but got %v", expected, snapshot)
+		}
+		if q.Len() != len(keys) {
+			t.Fatalf("expected pq length %d, but got %d", len(keys), q.Len())
+		}
+	}
+}
+
+func TestDelMinWithChildren(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for n := 0; n < 2; n++ {
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	i, _, order, err := pq.MinInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 2 || order != 3 {
+		t.Fatalf("expected minimum 2 to root a tree of order 3, but got %d of order %d", i, order)
+	}
+	for expected := 2; expected < 10; expected++ {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+		checkHeap(t, pq)
+	}
+}
+
+func TestMinIndices(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pq.MinIndices(); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	keys := []float32{0.5, 0.2, 0.9, 0.2, 0.3, 0.2}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indices, err := pq.MinIndices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{1, 3, 5}
+	if !slices.Equal(indices, expected) {
+		t.Fatalf("expected %v, but got %v", expected, indices)
+	}
+}
+
+func TestMinOr(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := pq.MinIndexOr(-1); i != -1 {
+		t.Fatalf("expected default index -1, but got %d", i)
+	}
+	if key := pq.MinKeyOr(-1); key != -1 {
+		t.Fatalf("expected default key -1, but got %.1f", key)
+	}
+	if err := pq.Insert(4, 0.4); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(2, 0.2); err != nil {
+		t.Fatal(err)
+	}
+	if i := pq.MinIndexOr(-1); i != 2 {
+		t.Fatalf("expected index 2, but got %d", i)
+	}
+	if key := pq.MinKeyOr(-1); key != 0.2 {
+		t.Fatalf("expected key 0.2, but got %.1f", key)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var queues []*IndexFibonacciMinPQ
+	for q := 0; q < 3; q++ {
+		pq, err := NewIndexFibonacciMinPQ(3 * (q + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 3 * q; i < 3*(q+1); i++ {
+			if err := pq.Insert(i, float32(8-i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if q == 1 {
+			if _, err := pq.DelMin(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		queues = append(queues, pq)
+	}
+	pq, err := MergeIndexFibonacciMinPQ(queues...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Len() != 8 || pq.Cap() != 9 {
+		t.Fatalf("expected pq length 8 and capacity 9, but got %d and %d", pq.Len(), pq.Cap())
+	}
+	for _, q := range queues {
+		if !q.IsEmpty() {
+			t.Fatal("expected merged queue to be empty")
+		}
+	}
+	expectedDel := []int{8, 7, 6, 4, 3, 2, 1, 0}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestMergeSharedIndex(t *testing.T) {
+	var queues []*IndexFibonacciMinPQ
+	for q := 0; q < 3; q++ {
+		pq, err := NewIndexFibonacciMinPQ(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.Insert(q, 0.1); err != nil {
+			t.Fatal(err)
+		}
+		queues = append(queues, pq)
+	}
+	if err := queues[2].Insert(0, 0.2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeIndexFibonacciMinPQ(queues...); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	for _, q := range queues {
+		if q.Len() == 0 {
+			t.Fatal("expected queues to be unchanged")
+		}
+	}
+	if _, err := MergeIndexFibonacciMinPQ(queues[0], queues[0]); err == nil {
+		t.Fatal("expected error on repeated queue")
+	}
+}
+
+func TestDeleteAndReinsert(t *testing.T) {
+	const n = 64
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for round := 0; round < 500; round++ {
+		var x *node[float32]
+		var i int
+		if round%2 == 0 {
+			x = pq.min
+			if i, err = pq.DelMin(); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			i = r.Intn(n)
+			x = pq.nodes[i]
+			if err := pq.Delete(i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if x.prev != nil || x.next != nil || x.parent != nil || x.child != nil {
+			t.Fatalf("expected freed node %v to be detached", x)
+		}
+		checkHeap(t, pq)
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+	if pq.Len() != n {
+		t.Fatalf("expected pq length %d, but got %d", n, pq.Len())
+	}
+}
+
+func TestRemove(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	key, err := pq.Remove(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if key != keys[7] || pq.Contains(7) || pq.Len() != 7 {
+		t.Fatalf("expected key %f removed, but got %f and length %d", keys[7], key, pq.Len())
+	}
+	if _, err := pq.Remove(7); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if _, err := pq.Remove(10); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	expectedDel := []int{6, 4, 8, 0, 3, 5, 1}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestDeleteAll(t *testing.T) {
+	const n = 100
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	indices := r.Perm(n)[:40]
+	indices = slices.DeleteFunc(indices, func(i int) bool { return !pq.Contains(i) })
+	expected := pq.Clone()
+	for _, i := range indices {
+		if err := expected.Delete(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invalid := [][]int{
+		append([]int{indices[0]}, indices...),
+		append(slices.Clone(indices), n),
+	}
+	for _, testCase := range invalid {
+		if err := pq.DeleteAll(testCase); err == nil {
+			t.Fatalf("expected error on %v", testCase)
+		}
+		if pq.Len() != n-1 {
+			t.Fatalf("expected pq length %d, but got %d", n-1, pq.Len())
+		}
+	}
+	if err := pq.DeleteAll(indices); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !slices.Equal(pq.Slice(), expected.Slice()) {
+		t.Fatalf("expected %v, but got %v", expected.Slice(), pq.Slice())
+	}
+	if err := pq.DeleteAll(pq.Slice()); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func benchmarkDeleteAll(b *testing.B, deleteAll func(pq *IndexFibonacciMinPQ, indices []int) error) {
+	const n = 1000
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		pq.Insert(i, r.Float32())
+	}
+	pq.DelMin()
+	indices := slices.DeleteFunc(r.Perm(n)[:n/2], func(i int) bool { return !pq.Contains(i) })
+	b.ResetTimer()
+	for m := 0; m < b.N; m++ {
+		b.StopTimer()
+		c := pq.Clone()
+		b.StartTimer()
+		if err := deleteAll(c, indices); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeleteAll(b *testing.B) {
+	benchmarkDeleteAll(b, (*IndexFibonacciMinPQ).DeleteAll)
+}
+
+func BenchmarkDeleteAllByDelete(b *testing.B) {
+	benchmarkDeleteAll(b, func(pq *IndexFibonacciMinPQ, indices []int) error {
+		for _, i := range indices {
+			if err := pq.Delete(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestString(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := pq.String(); s != "pq{len=0,max=10}" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	other, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.25, 0.75, 0.25}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+		if err := other.Insert(len(keys)-1-i, keys[len(keys)-1-i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := "pq{len=4,max=10,min=1:0.25,pairs=[{1 0.25} {3 0.25} {0 0.5} {2 0.75}]}"
+	if s := pq.String(); s != expected {
+		t.Fatalf("expected %q, but got %q", expected, s)
+	}
+	if s := other.String(); !strings.HasSuffix(s, "pairs=[{1 0.25} {3 0.25} {0 0.5} {2 0.75}]}") {
+		t.Fatalf("unexpected string %q", s)
+	}
+}
+
+func BenchmarkInsertDelMin(b *testing.B) {
+	const n = 1000
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		pq.Insert(i, r.Float32())
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for m := 0; m < b.N; m++ {
+		i, err := pq.DelMin()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDelMinSequence(b *testing.B) {
+	const n = 1000
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for m := 0; m < b.N; m++ {
+		b.StopTimer()
+		for i := 0; i < n; i++ {
+			if err := pq.Insert(i, r.Float32()); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StartTimer()
+		for !pq.IsEmpty() {
+			if _, err := pq.DelMin(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestDelMax(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pq.DelMax(); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.6}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := []int{1, 3, 5, 0, 4}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMax()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestWithInsert(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := pq.String()
+	c, err := pq.WithInsert(7, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, c)
+	if pq.String() != before || pq.Contains(7) {
+		t.Fatalf("expected %s to be unchanged, but got %s", before, pq)
+	}
+	if i, err := c.MinIndex(); err != nil || i != 7 || c.Len() != 6 {
+		t.Fatalf("expected minimum 7 of 6 keys, but got %d of %d (%v)", i, c.Len(), err)
+	}
+	if _, err := pq.WithInsert(3, 0.5); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+}
+
+func TestWithoutMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.WithoutMin(); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := pq.String()
+	c, i, err := pq.WithoutMin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, c)
+	if pq.String() != before || !pq.Contains(0) {
+		t.Fatalf("expected %s to be unchanged, but got %s", before, pq)
+	}
+	if i != 0 || c.Contains(0) || c.Len() != 4 {
+		t.Fatalf("expected 0 to be deleted from copy, but got %d from %s", i, c)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[int]float32{3: 0.3, 17: 0.1, 42: 0.9, 64: 0.5, 99: 0.2}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	delete(keys, 17)
+	mapping := pq.Compact()
+	checkHeap(t, pq)
+	if pq.Cap() != 4 || pq.Len() != 4 || len(mapping) != 4 {
+		t.Fatalf("expected 4 keys in range 4, but got %d keys in range %d", pq.Len(), pq.Cap())
+	}
+	expected := map[int]int{3: 0, 42: 1, 64: 2, 99: 3}
+	for i, k := range keys {
+		j, ok := mapping[i]
+		if !ok || j != expected[i] {
+			t.Fatalf("expected %d to map to %d, but got %d", i, expected[i], j)
+		}
+		if key, err := pq.KeyOf(j); err != nil || key != k {
+			t.Fatalf("expected key %.1f of %d, but got %.1f (%v)", k, j, key, err)
+		}
+	}
+	expectedDel := []int{3, 0, 2, 1}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestWouldBeMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.WouldBeMin(0.5) {
+		t.Fatal("expected any key to be the minimum of an empty queue")
+	}
+	if err := pq.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		key      float32
+		expected bool
+	}{
+		{0.4, true},
+		{0.5, false},
+		{0.6, false},
+	}
+	for _, testCase := range testData {
+		if pq.WouldBeMin(testCase.key) != testCase.expected {
+			t.Fatalf("expected %v for key %.1f", testCase.expected, testCase.key)
+		}
+	}
+	if pq.Len() != 1 {
+		t.Fatalf("expected pq length 1, but got %d", pq.Len())
+	}
+}
+
+func TestBounded(t *testing.T) {
+	const n, k = 100, 10
+	if _, err := NewBoundedIndexFibonacciMinPQ(n, 0); err == nil {
+		t.Fatal("expected error on zero capacity")
+	}
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewBoundedIndexFibonacciMinPQ(n, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]float32, n)
+	for i := range keys {
+		keys[i] = r.Float32()
+		err := pq.Insert(i, keys[i])
+		if err != nil && !errors.Is(err, ErrKeyRejected) {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		smallest := slices.Clone(keys[:i+1])
+		slices.Sort(smallest)
+		if len(smallest) > k {
+			smallest = smallest[:k]
+		}
+		if errors.Is(err, ErrKeyRejected) && keys[i] < smallest[len(smallest)-1] {
+			t.Fatalf("expected key %f to be kept", keys[i])
+		}
+		var kept []float32
+		for _, p := range pq.SortedPairs() {
+			kept = append(kept, p.Key)
+		}
+		if !slices.Equal(kept, smallest) {
+			t.Fatalf("expected %v, but got %v", smallest, kept)
+		}
+	}
+}
+
+func TestBoundedInsertAll(t *testing.T) {
+	pq, err := NewBoundedIndexFibonacciMinPQ(10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := []Pair[float32]{{0, 0.5}, {1, 0.9}, {2, 0.1}, {3, 0.7}, {4, 0.3}, {5, 0.8}}
+	if err := pq.InsertAll(pairs); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !slices.Equal(pq.Slice(), []int{2, 4, 0}) {
+		t.Fatalf("expected [2 4 0], but got %v", pq.Slice())
+	}
+}
+
+func TestBoundedUnion(t *testing.T) {
+	pq, err := NewBoundedIndexFibonacciMinPQ(10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.InsertAll([]Pair[float32]{{0, 0.5}, {1, 0.9}}); err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQFromMap(10, map[int]float32{2: 0.1, 3: 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Union(other); err == nil {
+		t.Fatal("expected error on union exceeding the capacity")
+	}
+	checkHeap(t, pq)
+	checkHeap(t, other)
+	if pq.Len() != 2 || other.Len() != 2 {
+		t.Fatalf("expected both queues unchanged, but got %v and %v", pq, other)
+	}
+	if err := other.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Union(other); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !slices.Equal(pq.Slice(), []int{2, 0, 1}) || !other.IsEmpty() {
+		t.Fatalf("expected [2 0 1], but got %v", pq.Slice())
+	}
+}
+
+func TestBoundedUnionKeepMin(t *testing.T) {
+	pq, err := NewBoundedIndexFibonacciMinPQ(10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.InsertAll([]Pair[float32]{{0, 0.5}, {1, 0.9}}); err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIndexFibonacciMinPQFromMap(10, map[int]float32{0: 0.2, 2: 0.1, 3: 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.UnionKeepMin(other); err == nil {
+		t.Fatal("expected error on union exceeding the capacity")
+	}
+	checkHeap(t, pq)
+	checkHeap(t, other)
+	if pq.Len() != 2 || other.Len() != 3 {
+		t.Fatalf("expected both queues unchanged, but got %v and %v", pq, other)
+	}
+	if key, _ := pq.KeyOf(0); key != 0.5 {
+		t.Fatalf("expected key 0.5 unchanged, but got %f", key)
+	}
+	// The shared index 0 counts once, so the union holds exactly the capacity.
+	if err := other.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.UnionKeepMin(other); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !slices.Equal(pq.Slice(), []int{2, 0, 1}) || !other.IsEmpty() {
+		t.Fatalf("expected [2 0 1], but got %v", pq.Slice())
+	}
+	if key, _ := pq.KeyOf(0); key != 0.2 {
+		t.Fatalf("expected key 0.2, but got %f", key)
+	}
+}
+
+func TestContainsAllAny(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{1, 3, 5} {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testData := []struct {
+		indices []int
+		all     bool
+		any     bool
+	}{
+		{nil, true, false},
+		{[]int{1, 3, 5}, true, true},
+		{[]int{1, 2}, false, true},
+		{[]int{5, -1}, false, true},
+		{[]int{0, 2, 10}, false, false},
+	}
+	for _, testCase := range testData {
+		if pq.ContainsAll(testCase.indices...) != testCase.all {
+			t.Fatalf("expected ContainsAll %v for %v", testCase.all, testCase.indices)
+		}
+		if pq.ContainsAny(testCase.indices...) != testCase.any {
+			t.Fatalf("expected ContainsAny %v for %v", testCase.any, testCase.indices)
+		}
+	}
+}
+
+func TestSecondMin(t *testing.T) {
+	const n = 100
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.SecondMin(); err == nil {
+		t.Fatal("expected error on empty queue")
+	}
+	if err := pq.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.SecondMin(); err == nil {
+		t.Fatal("expected error on queue of one key")
+	}
+	for i := 1; i < n; i++ {
+		if err := pq.Insert(i, r.Float32()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for pq.Len() >= 2 {
+		pairs := pq.SortedPairs()
+		i, key, err := pq.SecondMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != pairs[1].Key || (i != pairs[1].Index && key != pairs[0].Key) {
+			t.Fatalf("expected second minimum %v, but got {%d %f}", pairs[1], i, key)
+		}
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		j := r.Intn(n)
+		if pq.Contains(j) {
+			if err := pq.DecreaseKey(j, pairs[0].Key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestBumpMin(t *testing.T) {
+	const n = 50
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pq.BumpMin(1); err != ErrEmpty {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	keys := make([]float32, n)
+	for i := range keys {
+		keys[i] = r.Float32()
+		if err := pq.Insert(i, keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := float32(0)
+	for step := 0; step < 500; step++ {
+		i, key, err := pq.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key < now || key != keys[i] {
+			t.Fatalf("expected event %d at %f not before %f", i, key, now)
+		}
+		now = key
+		keys[i] = now + r.Float32()
+		next, err := pq.BumpMin(keys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if j, _ := pq.MinIndex(); j != next {
+			t.Fatalf("expected new minimum %d, but got %d", j, next)
+		}
+	}
+	if _, err := pq.BumpMin(-1); !errors.Is(err, ErrKeyNotIncreased) {
+		t.Fatalf("expected %v, but got %v", ErrKeyNotIncreased, err)
+	}
+}
+
+func TestStable(t *testing.T) {
+	pq, err := NewStableIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := []int{7, 3, 12, 0, 19, 5, 8, 1, 14, 2}
+	for _, i := range order {
+		if err := pq.Insert(i, 0.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 10; i < 12; i++ {
+		if err := pq.Insert(i, 0.9); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(4, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.DelMin(); err != nil || i != 4 {
+		t.Fatalf("expected 4, but got %d (%v)", i, err)
+	}
+	checkHeap(t, pq)
+	if err := pq.DecreaseKey(11, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := append(slices.Clone(order), 11, 10)
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestTieBreakLIFO(t *testing.T) {
+	if _, err := NewIndexFibonacciMinPQWithTieBreak(10, TieBreakLIFO+1); err == nil {
+		t.Fatal("expected error on unknown tie-break policy")
+	}
+	pq, err := NewIndexFibonacciMinPQWithTieBreak(20, TieBreakLIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := []int{7, 3, 12, 0, 19, 5, 8, 1, 14, 2}
+	for _, i := range order {
+		if err := pq.Insert(i, 0.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(4, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.DelMin(); err != nil || i != 4 {
+		t.Fatalf("expected 4, but got %d (%v)", i, err)
+	}
+	if err := pq.Insert(9, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := append([]int{9}, order...)
+	slices.Reverse(expectedDel[1:])
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestKthMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testData := []struct {
+		k   int
+		i   int
+		key float32
+	}{
+		{1, 2, 0.1},
+		{3, 0, 0.5},
+		{5, 1, 0.9},
+	}
+	for _, testCase := range testData {
+		i, key, err := pq.KthMin(testCase.k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != testCase.i || key != testCase.key {
+			t.Fatalf("expected %d with key %.1f, but got %d with key %.1f", testCase.i, testCase.key, i, key)
+		}
+	}
+	for _, k := range []int{0, 6} {
+		if _, _, err := pq.KthMin(k); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+		}
+	}
+	if pq.Len() != 5 {
+		t.Fatalf("expected pq length 5, but got %d", pq.Len())
+	}
+}
+
+func TestSparse(t *testing.T) {
+	const max = math.MaxInt
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := NewSparseIndexFibonacciMinPQ(max); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 4 {
+		t.Fatalf("expected constant allocations, but got %.0f", allocs)
+	}
+	pq, err := NewSparseIndexFibonacciMinPQ(max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := []int{max - 1, 0, 1 << 30, 12345, 1 << 20, 77}
+	for n, i := range indexes {
+		if err := pq.Insert(i, float32(len(indexes)-n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(max, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	if err := pq.DecreaseKey(max-1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Delete(12345); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if !pq.Contains(1<<30) || pq.Contains(12345) || pq.Cap() != max {
+		t.Fatalf("unexpected contents %v", pq)
+	}
+	c := pq.Clone()
+	if !pq.Equal(c) {
+		t.Fatalf("expected %v, but got %v", pq, c)
+	}
+	data, err := json.Marshal(pq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewSparseIndexFibonacciMinPQ(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		t.Fatal(err)
+	}
+	if !pq.Equal(r) {
+		t.Fatalf("expected %v, but got %v", pq, r)
+	}
+	expected := []int{max - 1, 77, 1 << 20, 1 << 30, 0}
+	if !slices.Equal(pq.Slice(), expected) {
+		t.Fatalf("expected %v, but got %v", expected, pq.Slice())
+	}
+	mapping := c.Compact()
+	checkHeap(t, c)
+	if c.Cap() != 5 || mapping[max-1] != 4 || mapping[0] != 0 {
+		t.Fatalf("unexpected mapping %v", mapping)
+	}
+	for _, expected := range expected {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestSwapKeys(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SwapKeys(1, 5); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if i, err := pq.MinIndex(); err != nil || i != 5 {
+		t.Fatalf("expected minimum 5, but got %d (%v)", i, err)
+	}
+	if err := pq.SwapKeys(9, 9); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.SwapKeys(0, 3); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if err := pq.SwapKeys(3, 10); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	expectedDel := []int{5, 2, 3, 4, 1, 6, 7, 8, 9}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestKeyVector(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[int]float32{1: 0.1, 4: 0.4, 5: 0.05}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inf := float32(math.Inf(1))
+	v := pq.KeyVector(inf)
+	expected := []float32{inf, 0.1, inf, inf, 0.4, 0.05}
+	if !slices.Equal(v, expected) {
+		t.Fatalf("expected %v, but got %v", expected, v)
+	}
+	nan := float32(math.NaN())
+	for i, k := range pq.KeyVector(nan) {
+		if key, ok := keys[i]; ok && k != key || !ok && !math.IsNaN(float64(k)) {
+			t.Fatalf("unexpected key %f at %d", k, i)
+		}
+	}
+}
+
+func TestSetReversed(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	pq.SetRecorder(&log)
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if i, err := pq.DelMin(); err != nil || i != 0 {
+		t.Fatalf("expected 0, but got %d (%v)", i, err)
+	}
+	if err := pq.SetReversed(true); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if i, err := pq.DelMin(); err != nil || i != 9 {
+		t.Fatalf("expected 9, but got %d (%v)", i, err)
+	}
+	if err := pq.DecreaseKey(5, 10); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.DelMin(); err != nil || i != 5 {
+		t.Fatalf("expected 5, but got %d (%v)", i, err)
+	}
+	if err := pq.SetReversed(false); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	r, err := ReplayIndexFibonacciMinPQ(10, strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.Equal(r) {
+		t.Fatalf("expected %v, but got %v", pq, r)
+	}
+	for i := range pq.Drain() {
+		if i != 1 {
+			t.Fatalf("expected 1, but got %d", i)
+		}
+		if err := pq.SetReversed(true); err == nil {
+			t.Fatal("expected error reversing while drained")
+		}
+		break
+	}
+	for range pq.All() {
+		if err := pq.SetReversed(true); err == nil {
+			t.Fatal("expected error reversing while iterated")
+		}
+	}
+	pq.ForEach(func(int, float32) bool {
+		if err := pq.SetReversed(true); err == nil {
+			t.Fatal("expected error reversing in ForEach")
+		}
+		return false
+	})
+	checkHeap(t, pq)
+	if err := pq.SetReversed(true); err != nil {
+		t.Fatal(err)
+	}
+	expectedDel := []int{8, 7, 6, 4, 3, 2}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
+
+func TestDelMinBatch(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := pq.Insert(i, float32((i*37)%100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := pq.DelMinBatch(-1); err == nil {
+		t.Fatal("expected error for negative batch size")
+	}
+	indexes, keys, err := pq.DelMinBatch(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if len(indexes) != 30 || len(keys) != 30 || pq.Len() != 70 {
+		t.Fatalf("expected 30 deleted and 70 left, but got %d, %d and %d", len(indexes), len(keys), pq.Len())
+	}
+	for j, key := range keys {
+		if key != float32(j) || (indexes[j]*37)%100 != j {
+			t.Fatalf("expected key %d, but got %d with key %f", j, indexes[j], key)
+		}
+	}
+	if err := pq.DecreaseKey(indexes[0], 0); err == nil {
+		t.Fatal("expected error decreasing a key deleted in batch")
+	}
+	indexes, keys, err = pq.DelMinBatch(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if len(indexes) != 70 || !pq.IsEmpty() {
+		t.Fatalf("expected 70 deleted and empty queue, but got %d and length %d", len(indexes), pq.Len())
+	}
+	for j, key := range keys {
+		if key != float32(j+30) {
+			t.Fatalf("expected key %d, but got %f", j+30, key)
+		}
+		if pq.Contains(indexes[j]) {
+			t.Fatalf("expected %d to be deleted", indexes[j])
+		}
+	}
+	indexes, keys, err = pq.DelMinBatch(5)
+	if err != nil || len(indexes) != 0 || len(keys) != 0 {
+		t.Fatalf("expected empty batch, but got %v %v (%v)", indexes, keys, err)
+	}
+}
+
+func TestIndicesWithKey(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.2, 0.5, 0.7, 0.2, 0.5}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		key      float32
+		expected []int
+	}{
+		{0.5, []int{0, 2, 5}},
+		{0.2, []int{4}},
+		{0.7, []int{3}},
+		{0.3, []int{}},
+	}
+	for _, tc := range testData {
+		result := pq.IndicesWithKey(tc.key)
+		if result == nil || !slices.Equal(result, tc.expected) {
+			t.Fatalf("expected %v for key %f, but got %v", tc.expected, tc.key, result)
+		}
+	}
+}
+
+func TestInRange(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7}
+	for i, k := range keys {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		lo, hi   float32
+		expected []int
+	}{
+		{0.2, 0.4, []int{1, 2, 3}},
+		{0.4, 0.4, []int{3}},
+		{0, 0.1, []int{}},
+		{0.7, 1, []int{6}},
+		{0.45, 0.65, []int{4, 5}},
+		{0.5, 0.3, []int{}},
+	}
+	for _, tc := range testData {
+		result := pq.InRange(tc.lo, tc.hi)
+		slices.Sort(result)
+		if result == nil || !slices.Equal(result, tc.expected) {
+			t.Fatalf("expected %v for range [%f, %f], but got %v", tc.expected, tc.lo, tc.hi, result)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pq.Split(0); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if _, err := pq.Split(20); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	// Split off an inner node having children.
+	var i int
+	for x := range pq.all() {
+		if x.parent != nil && x.order > 0 {
+			i = x.index
+			break
+		}
+	}
+	if i == 0 {
+		t.Fatal("expected an inner node with children")
+	}
+	r, err := pq.Split(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	checkHeap(t, r)
+	if r.Len() < 2 || pq.Len()+r.Len() != 19 || r.Cap() != 20 {
+		t.Fatalf("expected 19 keys split in two, but got %d and %d", pq.Len(), r.Len())
+	}
+	if m, err := r.MinIndex(); err != nil || m != i {
+		t.Fatalf("expected %d, but got %d (%v)", i, m, err)
+	}
+	seen := make(map[int]bool)
+	for _, q := range []*IndexFibonacciMinPQ{pq, r} {
+		prev := float32(-1)
+		for !q.IsEmpty() {
+			j, key, err := q.DelMinWithKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkHeap(t, q)
+			if key < prev || seen[j] {
+				t.Fatalf("unexpected %d with key %f after %f", j, key, prev)
+			}
+			prev = key
+			seen[j] = true
+		}
+	}
+	if len(seen) != 19 {
+		t.Fatalf("expected 19 keys, but got %d", len(seen))
+	}
+	if err := pq.Insert(5, 5); err != nil {
+		t.Fatal(err)
+	}
+	r, err = pq.Split(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.IsEmpty() || r.Len() != 1 {
+		t.Fatalf("expected the only key to be split, but got %d and %d", pq.Len(), r.Len())
+	}
+	checkHeap(t, pq)
+	checkHeap(t, r)
+}
+
+func TestLazy(t *testing.T) {
+	const n = 500
+	r := rand.New(rand.NewSource(1))
+	lazy, err := NewLazyIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eager, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for m := 0; m < 20*n; m++ {
+		i := r.Intn(n)
+		key := float32(r.Intn(n))
+		switch {
+		case !lazy.Contains(i):
+			if err := lazy.Insert(i, key); err != nil {
+				t.Fatal(err)
+			}
+			if err := eager.Insert(i, key); err != nil {
+				t.Fatal(err)
+			}
+		case m%3 == 0:
+			if err := lazy.ChangeKey(i, key); err != nil {
+				t.Fatal(err)
+			}
+			if err := eager.ChangeKey(i, key); err != nil {
+				t.Fatal(err)
+			}
+		default:
+			lazyKey, err := lazy.MinKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			eagerKey, err := eager.MinKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lazyKey != eagerKey {
+				t.Fatalf("expected minimum %f, but got %f", eagerKey, lazyKey)
+			}
+			limit := lazy.rootLimit()
+			j, err := lazy.DelMin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := eager.Delete(j); err != nil {
+				t.Fatal(err)
+			}
+			if lazy.RootCount() > limit {
+				t.Fatalf("expected at most %d roots, but got %d", limit, lazy.RootCount())
+			}
+		}
+		if m%100 == 0 {
+			checkHeap(t, lazy)
+		}
+	}
+	checkHeap(t, lazy)
+	if !lazy.Equal(eager) {
+		t.Fatalf("expected %v, but got %v", eager, lazy)
+	}
+	c := lazy.Clone()
+	if !c.lazy {
+		t.Fatal("expected clone to be lazy")
+	}
+	checkSameDrain(t, lazy, c)
+}
+
+func benchmarkDelMinHeavy(b *testing.B, pq *IndexFibonacciMinPQ) {
+	const n = 1000
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	for m := 0; m < b.N; m++ {
+		b.StopTimer()
+		for i := 0; i < n; i++ {
+			if err := pq.Insert(i, r.Float32()); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StartTimer()
+		for j := 0; j < n/2; j++ {
+			if _, err := pq.DelMin(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StopTimer()
+		pq.Clear()
+		b.StartTimer()
+	}
+}
+
+func BenchmarkDelMinEager(b *testing.B) {
+	pq, err := NewIndexFibonacciMinPQ(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkDelMinHeavy(b, pq)
+}
+
+func BenchmarkDelMinLazy(b *testing.B) {
+	pq, err := NewLazyIndexFibonacciMinPQ(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkDelMinHeavy(b, pq)
+}
+
+func TestMapped(t *testing.T) {
+	pq := NewMappedIndexFibonacciMinPQ()
+	indexes := []int{math.MaxInt, -7, math.MaxInt / 3, math.MinInt, 0, math.MaxInt - 1}
+	for n, i := range indexes {
+		if err := pq.Insert(i, float32(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.Insert(-7, 0); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	if err := pq.DecreaseKey(math.MaxInt-1, -1); err != nil {
+		t.Fatal(err)
+	}
+	if k, err := pq.KeyOf(math.MaxInt / 3); err != nil || k != 2 {
+		t.Fatalf("expected 2, but got %f (%v)", k, err)
+	}
+	if _, err := pq.KeyOf(42); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if err := pq.Delete(math.MinInt); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	if pq.Contains(math.MinInt) || !pq.Contains(-7) || pq.Cap() != 0 || pq.KeyVector(0) != nil {
+		t.Fatalf("unexpected queue %v", pq)
+	}
+	if err := pq.Grow(10); err == nil {
+		t.Fatal("expected error growing a mapped queue")
+	}
+	if _, err := pq.WriteTo(io.Discard); err == nil {
+		t.Fatal("expected error writing a mapped queue")
+	}
+	c := pq.Clone()
+	checkSameDrain(t, c, pq.Clone())
+	dense, err := NewIndexFibonacciMinPQ(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dense.Insert(3, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := dense.Union(pq); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	if err := pq.Union(dense); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	expectedDel := []int{math.MaxInt - 1, math.MaxInt, 3, -7, math.MaxInt / 3, 0}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Fatalf("expected empty queue, but got length %d", pq.Len())
+	}
+}
+
+func TestDelMinIf(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok, err := pq.DelMinIf(func(float32) bool { return true }); ok || !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %t (%v)", ErrEmpty, ok, err)
+	}
+	fireTimes := []float32{30, 10, 50, 20, 40, 10}
+	for i, k := range fireTimes {
+		if err := pq.Insert(i, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := float32(25)
+	due := func(key float32) bool {
+		return key <= now
+	}
+	var fired []float32
+	for {
+		_, key, ok, err := pq.DelMinIf(due)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		checkHeap(t, pq)
+		fired = append(fired, key)
+	}
+	if !slices.Equal(fired, []float32{10, 10, 20}) {
+		t.Fatalf("expected 10, 10 and 20 fired, but got %v", fired)
+	}
+	if i, key, ok, err := pq.DelMinIf(due); ok || err != nil || pq.Len() != 3 {
+		t.Fatalf("expected no key deleted, but got %d with key %f (%v)", i, key, err)
+	}
+	now = 40
+	if i, key, ok, err := pq.DelMinIf(due); !ok || err != nil || i != 0 || key != 30 {
+		t.Fatalf("expected 0 with key 30, but got %d with key %f (%v)", i, key, err)
+	}
+	if _, _, _, err := pq.DelMinIf(nil); err == nil {
+		t.Fatal("expected error for nil predicate")
+	}
+}
+
+func TestReplaceMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQWithOpCounts(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.ReplaceMin(0, 1); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+	other, err := NewIndexFibonacciMinPQ(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		key := float32((i * 37) % 50)
+		if err := pq.Insert(i, key); err != nil {
+			t.Fatal(err)
+		}
+		if err := other.Insert(i, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 50; i < 100; i++ {
+		key := float32((i*13)%50) + 0.5
+		_, _, before := pq.OpCounts()
+		oldIndex, oldKey, err := pq.ReplaceMin(i, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, after := pq.OpCounts(); after-before != 1 {
+			t.Fatalf("expected 1 consolidation, but got %d", after-before)
+		}
+		expectedIndex, expectedKey, err := other.DelMinWithKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := other.Insert(i, key); err != nil {
+			t.Fatal(err)
+		}
+		if oldIndex != expectedIndex || oldKey != expectedKey {
+			t.Fatalf("expected %d with key %f, but got %d with key %f", expectedIndex, expectedKey, oldIndex, oldKey)
+		}
+		if pq.Contains(oldIndex) || !pq.Contains(i) || pq.Len() != 50 {
+			t.Fatalf("expected %d replaced by %d, but got %v", oldIndex, i, pq)
+		}
+		checkHeap(t, pq)
+	}
+	min, err := pq.MinIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pq.ReplaceMin(min, 0); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	if _, _, err := pq.ReplaceMin(100, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	checkSameDrain(t, pq, other)
+}
+
+func TestIndices(t *testing.T) {
+	for _, sparse := range []bool{false, true} {
+		pq, err := newIndexFibonacciPQ[float32](20, sparse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if indices := pq.Indices(); len(indices) != 0 {
+			t.Fatalf("expected no index, but got %v", indices)
+		}
+		inserted := []int{13, 2, 19, 7, 0, 11, 5}
+		for n, i := range inserted {
+			if err := pq.Insert(i, float32(n)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.DecreaseKey(5, -1); err != nil {
+			t.Fatal(err)
+		}
+		expected := []int{0, 2, 5, 7, 11, 19}
+		if indices := pq.Indices(); !slices.Equal(indices, expected) {
+			t.Fatalf("expected %v, but got %v", expected, indices)
+		}
+	}
+}
+
+func TestDecreaseKeyToMin(t *testing.T) {
+	// Indexes 7 then 1 are decreased to the minimum key of index 2.
+	testData := []struct {
+		ties     TieBreak
+		expected []int
+	}{
+		{TieBreakNone, []int{2, 2}},
+		{TieBreakFIFO, []int{2, 1}},
+		{TieBreakLIFO, []int{7, 7}},
+	}
+	for _, tc := range testData {
+		pq, err := NewIndexFibonacciMinPQWithTieBreak(10, tc.ties)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			if err := pq.Insert(i, float32(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.DecreaseKey(1, 0.5); err != nil {
+			t.Fatal(err)
+		}
+		if err := pq.DecreaseKey(2, 0.25); err != nil {
+			t.Fatal(err)
+		}
+		for n, i := range []int{7, 1} {
+			if err := pq.DecreaseKey(i, 0.25); err != nil {
+				t.Fatal(err)
+			}
+			checkHeap(t, pq)
+			if m, err := pq.MinIndex(); err != nil || m != tc.expected[n] {
+				t.Fatalf("expected %d, but got %d (%v)", tc.expected[n], m, err)
+			}
+			if k, err := pq.MinKey(); err != nil || k != 0.25 {
+				t.Fatalf("expected 0.25, but got %f (%v)", k, err)
+			}
+		}
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	const n = 200
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pq.Rebuild()
+	checkHeap(t, pq)
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < n; i++ {
+		if err := pq.Insert(i, r.Float32()+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i += 3 {
+		if pq.Contains(i) {
+			if err := pq.DecreaseKey(i, r.Float32()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if s := pq.Stats(); s.Marked == 0 || s.Trees >= pq.Len() {
+		t.Fatalf("expected a complex heap, but got %+v", s)
+	}
+	c := pq.Clone()
+	pq.Rebuild()
+	checkHeap(t, pq)
+	if s := pq.Stats(); s.Trees != pq.Len() || s.Marked != 0 || s.MaxOrder != 0 {
+		t.Fatalf("expected a flat root list, but got %+v", s)
+	}
+	if !pq.Equal(c) {
+		t.Fatalf("expected %v, but got %v", c, pq)
+	}
+	// Corrupt the links of the heap, rebuilding recovers from the index array.
+	for _, q := range []*IndexFibonacciMinPQ{pq, c} {
+		if _, err := q.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for x := range pq.all() {
+		x.next, x.prev = x, x
+	}
+	pq.Rebuild()
+	checkHeap(t, pq)
+	checkSameDrain(t, c, pq)
+}
@@ -0,0 +1,33 @@
+package heap // import "kkn.fi/heap"
+
+import "iter"
+
+// All returns an iterator over every index in the priority queue along with its key.
+// The order of the iteration is unspecified.
+// The priority queue must not be modified during the iteration.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) All() iter.Seq2[int, K] {
+	return func(yield func(int, K) bool) {
+		pq.ForEach(yield)
+	}
+}
+
+// Drain returns an iterator that deletes the minimum key and yields the deleted index and key,
+// until the priority queue is empty or the iteration is stopped.
+// The entries not yet yielded remain in the queue.
+// Worst case is O(n log(n)).
+func (pq *IndexFibonacciPQ[K]) Drain() iter.Seq2[int, K] {
+	return func(yield func(int, K) bool) {
+		pq.visiting++
+		defer func() { pq.visiting-- }()
+		for !pq.IsEmpty() {
+			i, key, err := pq.DelMinWithKey()
+			if err != nil {
+				return
+			}
+			if !yield(i, key) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package heap
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seen := make(map[int]bool)
+	for i, key := range pq.All() {
+		if key != float32(i) {
+			t.Fatalf("expected key %d of %d, but got %.1f", i, i, key)
+		}
+		seen[i] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf("expected 10 indexes, but got %d", len(seen))
+	}
+	n := 0
+	for range pq.All() {
+		n++
+		if n == 4 {
+			break
+		}
+	}
+	if n != 4 || pq.Len() != 10 {
+		t.Fatalf("expected 4 iterations over 10 keys, but got %d over %d", n, pq.Len())
+	}
+}
+
+func TestDrain(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(9-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var drained []int
+	for i, key := range pq.Drain() {
+		if key != float32(9-i) {
+			t.Fatalf("expected key %d for %d, but got %.1f", 9-i, i, key)
+		}
+		drained = append(drained, i)
+	}
+	if len(drained) != 10 || drained[0] != 9 || drained[9] != 0 {
+		t.Fatalf("expected indexes in descending order, but got %v", drained)
+	}
+	if !pq.IsEmpty() {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestDrainBreak(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range pq.Drain() {
+		if i == 2 {
+			break
+		}
+	}
+	if pq.Len() != 7 {
+		t.Fatalf("expected pq length 7, but got %d", pq.Len())
+	}
+	if i, err := pq.MinIndex(); err != nil || i != 3 {
+		t.Fatalf("expected minimum index 3, but got %d (%v)", i, err)
+	}
+}
@@ -0,0 +1,236 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+)
+
+// pqState is the portable representation of the logical contents of a priority queue.
+type pqState[K cmp.Ordered] struct {
+	Max     int       `json:"max"`
+	Entries []Pair[K] `json:"entries"`
+}
+
+// MarshalJSON encodes the index range and the index and key pairs of the priority queue as JSON.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) MarshalJSON() ([]byte, error) {
+	v := pqState[K]{
+		Max:     pq.max,
+		Entries: pq.pairs(),
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON replaces the contents of the priority queue with the index range and
+// the index and key pairs decoded from JSON. The key ordering of the priority queue is retained.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) UnmarshalJSON(data []byte) error {
+	var v pqState[K]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return pq.restore(v.Max, v.Entries)
+}
+
+// WriteSortedJSON writes the index and key pairs of the priority queue to w as a JSON array
+// in ascending order of keys. The pairs are encoded one at a time while a clone of the
+// priority queue is drained, so no slice of pairs is materialized.
+// The priority queue is not modified.
+// Worst case is O(n log(n)).
+func (pq IndexFibonacciPQ[K]) WriteSortedJSON(w io.Writer) error {
+	c := pq.Clone()
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for n := 0; !c.IsEmpty(); n++ {
+		i, key, err := c.DelMinWithKey()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(Pair[K]{Index: i, Key: key})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			bw.WriteByte(',')
+		}
+		bw.Write(data)
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
+
+// GobEncode encodes the index range and the index and key pairs of the priority queue with gob.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) GobEncode() ([]byte, error) {
+	v := pqState[K]{
+		Max:     pq.max,
+		Entries: pq.pairs(),
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode replaces the contents of the priority queue with the index range and
+// the index and key pairs decoded with gob. The key ordering of the priority queue is retained.
+// Worst case is O(n).
+func (pq *IndexFibonacciPQ[K]) GobDecode(data []byte) error {
+	var v pqState[K]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return err
+	}
+	return pq.restore(v.Max, v.Entries)
+}
+
+// binaryHeader is the header of the binary representation of a priority queue.
+type binaryHeader struct {
+	Max    int64
+	Length int64
+}
+
+// binaryPair is the binary representation of an index and the key associated with it.
+type binaryPair[K cmp.Ordered] struct {
+	Index int32
+	Key   K
+}
+
+// WriteTo writes the index range and the index and key pairs of the priority queue to w
+// in a compact little-endian binary format, returns the number of bytes written.
+// Keys must be of a fixed size type, such as float32 or float64.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) WriteTo(w io.Writer) (int64, error) {
+	if pq.mapped {
+		return 0, errors.New("cannot write a priority queue of unbounded index range")
+	}
+	if pq.max > math.MaxInt32 {
+		return 0, errors.New("cannot write a priority queue with indexes out of the int32 range")
+	}
+	pairs := make([]binaryPair[K], 0, pq.length)
+	for _, p := range pq.pairs() {
+		pairs = append(pairs, binaryPair[K]{Index: int32(p.Index), Key: p.Key})
+	}
+	if binary.Size(pairs) < 0 {
+		return 0, errors.New("cannot write keys of variable size")
+	}
+	var buf bytes.Buffer
+	h := binaryHeader{Max: int64(pq.max), Length: int64(len(pairs))}
+	if err := binary.Write(&buf, binary.LittleEndian, h); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, pairs); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// ReadIndexFibonacciMinPQFrom reads a priority queue of float32 keys written by WriteTo from r,
+// returns the priority queue and the number of bytes read.
+// Worst case is O(n).
+func ReadIndexFibonacciMinPQFrom(r io.Reader) (*IndexFibonacciMinPQ, int64, error) {
+	return ReadIndexFibonacciPQFrom[float32](r)
+}
+
+// ReadIndexFibonacciPQFrom reads a priority queue written by WriteTo from r,
+// returns the priority queue and the number of bytes read.
+// The header is not trusted: the pairs are read in chunks of bounded size, and a priority queue
+// whose index range is much larger than its length is read as a sparse priority queue,
+// so that memory grows with the bytes actually read.
+// Worst case is O(n).
+func ReadIndexFibonacciPQFrom[K cmp.Ordered](r io.Reader) (*IndexFibonacciPQ[K], int64, error) {
+	var h binaryHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, 0, err
+	}
+	n := int64(binary.Size(h))
+	if h.Max < 0 || h.Max > math.MaxInt32 || h.Length < 0 || h.Length > h.Max {
+		return nil, n, errors.New("invalid priority queue header")
+	}
+	var pairs []binaryPair[K]
+	chunk := make([]binaryPair[K], min(h.Length, readChunkSize))
+	for remaining := h.Length; remaining > 0; remaining -= int64(len(chunk)) {
+		chunk = chunk[:min(remaining, readChunkSize)]
+		if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+			return nil, n, err
+		}
+		n += int64(binary.Size(chunk))
+		pairs = append(pairs, chunk...)
+	}
+	sparse := h.Max > 8*(int64(len(pairs))+readChunkSize)
+	pq, err := newIndexFibonacciPQ[K](int(h.Max), sparse)
+	if err != nil {
+		return nil, n, err
+	}
+	for _, p := range pairs {
+		if err := pq.Insert(int(p.Index), p.Key); err != nil {
+			return nil, n, err
+		}
+	}
+	return pq, n, nil
+}
+
+// readChunkSize is the maximum number of pairs read at once by ReadIndexFibonacciPQFrom.
+const readChunkSize = 4096
+
+// pairs returns the index and key pairs of the priority queue in ascending order of indexes.
+func (pq IndexFibonacciPQ[K]) pairs() []Pair[K] {
+	result := make([]Pair[K], 0, pq.length)
+	for n := range pq.all() {
+		result = append(result, Pair[K]{Index: n.index, Key: n.key})
+	}
+	return result
+}
+
+// restore replaces the contents of the priority queue with a queue of the given index range
+// holding the given index and key pairs. Only the heap is replaced: the ordering, the recorder
+// and the callback of the priority queue are kept. A bounded priority queue rejects more pairs
+// than its capacity rather than evicting keys. The restore is recorded as a clear followed by
+// the change of the index range, if any, and the insertion of every pair.
+// The priority queue is left unchanged on error.
+func (pq *IndexFibonacciPQ[K]) restore(max int, pairs []Pair[K]) (err error) {
+	if err := pq.checkCapacity(len(pairs)); err != nil {
+		return err
+	}
+	r, err := newIndexFibonacciPQ[K](max, pq.sparse != nil)
+	if err != nil {
+		return err
+	}
+	r.less = pq.less
+	r.rev = pq.rev
+	r.ties = pq.ties
+	r.lesser = pq.lesser
+	r.mapped = pq.mapped
+	for _, p := range pairs {
+		if err := r.Insert(p.Index, p.Key); err != nil {
+			return err
+		}
+	}
+	defer pq.notifyMinChange(pq.beginMinChange(), &err)
+	pq.head = r.head
+	pq.min = r.min
+	pq.length = r.length
+	pq.table = r.table
+	pq.seq = r.seq
+	pq.nodes = r.nodes
+	pq.sparse = r.sparse
+	pq.record("clear")
+	switch {
+	case max > pq.max:
+		pq.record("grow %d", max)
+	case max < pq.max:
+		pq.record("shrink %d", max)
+	}
+	pq.max = max
+	for _, p := range pairs {
+		pq.record("insert %d %v", p.Index, p.Key)
+	}
+	return nil
+}
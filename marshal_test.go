@@ -0,0 +1,346 @@
+package heap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4, 0.05}
+	for i, k := range keys {
+		if err := pq.Insert(i*2, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(pq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r IndexFibonacciMinPQ
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != pq.Len() {
+		t.Fatalf("expected length %d, but got %d", pq.Len(), r.Len())
+	}
+	if r.max != 20 {
+		t.Fatalf("expected max 20, but got %d", r.max)
+	}
+	checkSameDrain(t, pq, &r)
+}
+
+func TestJSONUnmarshalInvalid(t *testing.T) {
+	testData := []string{
+		`{"max":-1,"entries":[]}`,
+		`{"max":2,"entries":[{"index":2,"key":0.1}]}`,
+		`{"max":2,"entries":[{"index":1,"key":0.1},{"index":1,"key":0.2}]}`,
+		`{"max":2,"entries":`,
+	}
+	for _, data := range testData {
+		var pq IndexFibonacciMinPQ
+		if err := json.Unmarshal([]byte(data), &pq); err == nil {
+			t.Fatalf("expected error unmarshaling %s", data)
+		}
+	}
+}
+
+// checkSameDrain drains both queues verifying they yield the same indexes and keys.
+func checkSameDrain(t *testing.T, pq, other *IndexFibonacciMinPQ) {
+	t.Helper()
+	for !pq.IsEmpty() {
+		i, key, err := pq.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		j, otherKey, err := other.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != j || key != otherKey {
+			t.Fatalf("expected %d with key %f, but got %d with key %f", i, key, j, otherKey)
+		}
+		if _, err := pq.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !other.IsEmpty() {
+		t.Fatalf("expected empty queue, but got length %d", other.Len())
+	}
+}
+
+func TestUnmarshalKeepsHooks(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQFromMap(10, map[int]float32{0: 0.5, 3: 0.1, 7: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(pq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gobData, err := pq.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoders := map[string]func(r *IndexFibonacciMinPQ) error{
+		"json": func(r *IndexFibonacciMinPQ) error { return json.Unmarshal(data, r) },
+		"gob":  func(r *IndexFibonacciMinPQ) error { return r.GobDecode(gobData) },
+	}
+	for name, decode := range decoders {
+		r, err := NewBoundedIndexFibonacciMinPQ(10, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		r.SetRecorder(&buf)
+		var changes [][2]int
+		r.OnMinChange(func(oldIndex, newIndex int) {
+			changes = append(changes, [2]int{oldIndex, newIndex})
+		})
+		if err := decode(r); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, r)
+		if !r.Equal(pq) {
+			t.Fatalf("%s: expected %v, but got %v", name, pq, r)
+		}
+		if expected := [][2]int{{-1, 3}}; !slices.Equal(changes, expected) {
+			t.Fatalf("%s: expected %v, but got %v", name, expected, changes)
+		}
+		if _, err := r.DelMin(); err != nil {
+			t.Fatal(err)
+		}
+		expected := "clear\ninsert 0 0.5\ninsert 3 0.1\ninsert 7 0.9\ndelmin\n"
+		if buf.String() != expected || len(changes) != 2 {
+			t.Fatalf("%s: expected the recorder and the callback kept, but got %q and %v", name, buf.String(), changes)
+		}
+		replayed, err := ReplayIndexFibonacciMinPQ(10, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !replayed.Equal(r) {
+			t.Fatalf("%s: expected %v replayed, but got %v", name, r, replayed)
+		}
+	}
+}
+
+func TestUnmarshalOverCapacity(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQFromMap(10, map[int]float32{0: 0.5, 3: 0.1, 7: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(pq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gobData, err := pq.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoders := map[string]func(r *IndexFibonacciMinPQ) error{
+		"json": func(r *IndexFibonacciMinPQ) error { return json.Unmarshal(data, r) },
+		"gob":  func(r *IndexFibonacciMinPQ) error { return r.GobDecode(gobData) },
+	}
+	for name, decode := range decoders {
+		r, err := NewBoundedIndexFibonacciMinPQ(10, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Insert(5, 0.3); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		r.SetRecorder(&buf)
+		if err := decode(r); err == nil {
+			t.Fatalf("%s: expected error decoding 3 keys into a queue of capacity 2", name)
+		}
+		checkHeap(t, r)
+		if r.Len() != 1 || !r.Contains(5) || buf.Len() != 0 {
+			t.Fatalf("%s: expected the queue unchanged, but got %v and %q", name, r, buf.String())
+		}
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	gob.Register(&IndexFibonacciMinPQ{})
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4, 0.05}
+	for i, k := range keys {
+		if err := pq.Insert(i*2, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(6, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	var v interface{} = pq
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		t.Fatal(err)
+	}
+	var d interface{}
+	if err := gob.NewDecoder(&buf).Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := d.(*IndexFibonacciMinPQ)
+	if !ok {
+		t.Fatalf("expected *IndexFibonacciMinPQ, but got %T", d)
+	}
+	if r.Len() != pq.Len() || r.max != pq.max {
+		t.Fatalf("expected length %d and max %d, but got %d and %d", pq.Len(), pq.max, r.Len(), r.max)
+	}
+	checkSameDrain(t, pq, r)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4, 0.05}
+	for i, k := range keys {
+		if err := pq.Insert(i*2, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	written, err := pq.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(buf.Len()) || written != 16+9*8 {
+		t.Fatalf("expected %d bytes written, but got %d", 16+9*8, written)
+	}
+	r, read, err := ReadIndexFibonacciMinPQFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != written {
+		t.Fatalf("expected %d bytes read, but got %d", written, read)
+	}
+	checkHeap(t, r)
+	if !pq.Equal(r) {
+		t.Fatalf("expected %v, but got %v", pq, r)
+	}
+}
+
+func TestBinaryTruncated(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := pq.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for _, n := range []int{0, 10, 16, len(data) - 1} {
+		if _, _, err := ReadIndexFibonacciMinPQFrom(bytes.NewReader(data[:n])); err == nil {
+			t.Fatalf("expected error reading %d of %d bytes", n, len(data))
+		}
+	}
+	pqs, err := NewIndexFibonacciPQ[string](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pqs.WriteTo(&buf); err == nil {
+		t.Fatal("expected error writing keys of variable size")
+	}
+}
+
+func TestBinaryHugeHeader(t *testing.T) {
+	var buf bytes.Buffer
+	h := binaryHeader{Max: math.MaxInt32, Length: math.MaxInt32}
+	if err := binary.Write(&buf, binary.LittleEndian, h); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(make([]byte, 100))
+	if _, _, err := ReadIndexFibonacciMinPQFrom(&buf); err == nil {
+		t.Fatal("expected error reading a truncated body")
+	}
+	buf.Reset()
+	h = binaryHeader{Max: math.MaxInt32, Length: 1}
+	if err := binary.Write(&buf, binary.LittleEndian, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, binaryPair[float32]{Index: math.MaxInt32 - 1, Key: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	pq, _, err := ReadIndexFibonacciMinPQFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pq.Cap() != math.MaxInt32 || pq.Len() != 1 {
+		t.Fatalf("expected 1 key in %d indexes, but got %v", math.MaxInt32, pq)
+	}
+	if i, err := pq.MinIndex(); err != nil || i != math.MaxInt32-1 {
+		t.Fatalf("expected minimum %d, but got %d (%v)", math.MaxInt32-1, i, err)
+	}
+}
+
+func TestWriteSortedJSON(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pq.WriteSortedJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]" {
+		t.Fatalf("expected empty array, but got %s", buf.String())
+	}
+	keys := []float32{0.5, 0.9, 0.1, 0.7, 0.3, 0.8, 0.2, 0.6, 0.4, 0.05}
+	for i, k := range keys {
+		if err := pq.Insert(i*2, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.Reset()
+	if err := pq.WriteSortedJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []Pair[float32]
+	if err := json.Unmarshal(buf.Bytes(), &pairs); err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != len(keys) || pq.Len() != len(keys) {
+		t.Fatalf("expected %d pairs, but got %d and length %d", len(keys), len(pairs), pq.Len())
+	}
+	for n, p := range pairs {
+		if keys[p.Index/2] != p.Key {
+			t.Fatalf("expected key %f for %d, but got %f", keys[p.Index/2], p.Index, p.Key)
+		}
+		if n > 0 && pairs[n-1].Key > p.Key {
+			t.Fatalf("expected ascending keys, but got %f after %f", p.Key, pairs[n-1].Key)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package heap // import "kkn.fi/heap"
+
+// OnMinChange registers a callback invoked whenever the minimum moves to another index.
+// The callback receives the index of the minimum before and after the change, -1 standing
+// for an empty priority queue. It is called once per operation moving the minimum, such as
+// Insert, DelMin, Delete, DecreaseKey, IncreaseKey and their batch variants, Union, Split,
+// Rebuild, Clear, SetReversed and Compact, even if the operation calls other operations.
+// It is not called by operations that fail.
+// A nil callback removes the registered callback.
+func (pq *IndexFibonacciPQ[K]) OnMinChange(cb func(oldIndex, newIndex int)) {
+	pq.onMin = cb
+}
+
+// minIndexOrNone returns the index associated with the minimum key, or -1 if the queue is empty.
+func (pq *IndexFibonacciPQ[K]) minIndexOrNone() int {
+	if pq.min == nil {
+		return -1
+	}
+	return pq.min.index
+}
+
+// beginMinChange starts an operation that may move the minimum, returns the index of the
+// minimum to be passed to the deferred notifyMinChange ending the operation.
+func (pq *IndexFibonacciPQ[K]) beginMinChange() int {
+	pq.changing++
+	return pq.minIndexOrNone()
+}
+
+// notifyMinChange ends an operation started by beginMinChange. If it is the outermost operation
+// in progress, it calls the registered callback if the minimum moved from the given index,
+// unless the operation failed with the given error, if not nil. It is deferred before
+// recoverCorrupt, so that it runs after the recovery and panics of the callback are propagated.
+func (pq *IndexFibonacciPQ[K]) notifyMinChange(oldIndex int, err *error) {
+	pq.changing--
+	if pq.changing > 0 || pq.onMin == nil || (err != nil && *err != nil) {
+		return
+	}
+	if newIndex := pq.minIndexOrNone(); newIndex != oldIndex {
+		pq.onMin(oldIndex, newIndex)
+	}
+}
@@ -0,0 +1,143 @@
+package heap
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestOnMinChange(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes [][2]int
+	pq.OnMinChange(func(oldIndex, newIndex int) {
+		changes = append(changes, [2]int{oldIndex, newIndex})
+	})
+	steps := []func() error{
+		func() error { return pq.Insert(3, 0.5) },
+		func() error { return pq.Insert(4, 0.7) },
+		func() error { return pq.Insert(5, 0.2) },
+		func() error { return pq.DecreaseKey(4, 0.6) },
+		func() error { return pq.DecreaseKey(4, 0.1) },
+		func() error { return pq.IncreaseKey(4, 0.3) },
+		func() error { return pq.Delete(3) },
+		func() error { _, err := pq.DelMin(); return err },
+		func() error { return pq.Insert(1, 0.1) },
+		func() error { return pq.Delete(4) },
+		func() error { _, err := pq.DelMin(); return err },
+		func() error { return pq.Insert(1, 0.1) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(20, 0.1); err == nil {
+		t.Fatal("expected error inserting out of range")
+	}
+	expected := [][2]int{{-1, 3}, {3, 5}, {5, 4}, {4, 5}, {5, 4}, {4, 1}, {1, -1}, {-1, 1}, {1, -1}}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, but got %v", expected, changes)
+	}
+	pq.OnMinChange(nil)
+	if err := pq.Insert(2, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected no call after removing the callback, but got %v", changes[len(expected):])
+	}
+}
+
+func TestOnMinChangeBatch(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes [][2]int
+	pq.OnMinChange(func(oldIndex, newIndex int) {
+		changes = append(changes, [2]int{oldIndex, newIndex})
+	})
+	other, err := NewIndexFibonacciMinPQFromMap(20, map[int]float32{15: 0.01, 16: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var otherChanges [][2]int
+	other.OnMinChange(func(oldIndex, newIndex int) {
+		otherChanges = append(otherChanges, [2]int{oldIndex, newIndex})
+	})
+	testData := []struct {
+		name     string
+		step     func() error
+		expected [][2]int
+	}{
+		{"InsertAll", func() error {
+			return pq.InsertAll([]Pair[float32]{{0, 0.5}, {1, 0.3}, {2, 0.4}, {3, 0.6}, {4, 0.7}})
+		}, [][2]int{{-1, 1}}},
+		{"DecreaseKeys", func() error {
+			return pq.DecreaseKeys(map[int]float32{3: 0.2, 4: 0.1})
+		}, [][2]int{{1, 4}}},
+		{"DelMinBatch", func() error {
+			_, _, err := pq.DelMinBatch(2)
+			return err
+		}, [][2]int{{4, 1}}},
+		{"DeleteAll", func() error {
+			return pq.DeleteAll([]int{1, 2})
+		}, [][2]int{{1, 0}}},
+		{"Union", func() error {
+			return pq.Union(other)
+		}, [][2]int{{0, 15}}},
+		{"Split", func() error {
+			_, err := pq.Split(15)
+			return err
+		}, [][2]int{{15, 0}}},
+		{"SetReversed", func() error {
+			return pq.SetReversed(true)
+		}, [][2]int{{0, 16}}},
+		{"Clear", func() error {
+			pq.Clear()
+			return nil
+		}, [][2]int{{16, -1}}},
+	}
+	for _, tc := range testData {
+		changes = nil
+		if err := tc.step(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !slices.Equal(changes, tc.expected) {
+			t.Fatalf("%s: expected %v, but got %v", tc.name, tc.expected, changes)
+		}
+		checkHeap(t, pq)
+	}
+	if expected := [][2]int{{15, -1}}; !slices.Equal(otherChanges, expected) {
+		t.Fatalf("expected %v for the other queue, but got %v", expected, otherChanges)
+	}
+}
+
+func TestOnMinChangeBoundedEviction(t *testing.T) {
+	pq, err := NewBoundedIndexFibonacciMinPQ(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes [][2]int
+	pq.OnMinChange(func(oldIndex, newIndex int) {
+		changes = append(changes, [2]int{oldIndex, newIndex})
+	})
+	if err := pq.Insert(0, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	// Inserting 1 evicts the minimum 0, which is reported once along with the insertion.
+	if err := pq.Insert(1, 0.3); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Insert(2, 0.4); !errors.Is(err, ErrKeyRejected) {
+		t.Fatalf("expected %v, but got %v", ErrKeyRejected, err)
+	}
+	if expected := [][2]int{{-1, 0}, {0, 1}}; !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, but got %v", expected, changes)
+	}
+}
@@ -0,0 +1,120 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SetRecorder sets the writer that receives a line per mutating operation of the priority queue,
+// such as "insert 3 0.5", "delmin" or "decrease 3 0.2". A nil writer stops the recording.
+// Errors writing to the recorder are ignored.
+// The recorded operations are replayed by ReplayIndexFibonacciMinPQ.
+func (pq *IndexFibonacciPQ[K]) SetRecorder(w io.Writer) {
+	pq.recorder = w
+}
+
+// record writes a line describing a mutating operation to the recorder, if set.
+func (pq *IndexFibonacciPQ[K]) record(format string, args ...any) {
+	if pq.recorder == nil {
+		return
+	}
+	fmt.Fprintf(pq.recorder, format+"\n", args...)
+}
+
+// ReplayIndexFibonacciMinPQ initializes an indexed priority queue of float32 keys
+// with indices between 0 and given max-1 and applies the operations recorded in r.
+// An error identifies the line of the first operation that cannot be applied.
+// Worst case is O(m log(n)) (amortized) for m operations.
+func ReplayIndexFibonacciMinPQ(max int, r io.Reader) (*IndexFibonacciMinPQ, error) {
+	pq, err := NewIndexFibonacciMinPQ(max)
+	if err != nil {
+		return nil, err
+	}
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		if err := replay(pq, strings.Fields(s.Text())); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return pq, nil
+}
+
+// replay applies a recorded operation split in fields to the priority queue.
+func replay(pq *IndexFibonacciMinPQ, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	switch op, args := fields[0], fields[1:]; {
+	case op == "insert" && len(args) == 2:
+		i, key, err := parseIndexKey(args)
+		if err != nil {
+			return err
+		}
+		return pq.Insert(i, key)
+	case op == "decrease" && len(args) == 2:
+		i, key, err := parseIndexKey(args)
+		if err != nil {
+			return err
+		}
+		return pq.DecreaseKey(i, key)
+	case op == "increase" && len(args) == 2:
+		i, key, err := parseIndexKey(args)
+		if err != nil {
+			return err
+		}
+		return pq.IncreaseKey(i, key)
+	case op == "delete" && len(args) == 1:
+		i, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+		return pq.Delete(i)
+	case op == "grow" && len(args) == 1:
+		max, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+		return pq.Grow(max)
+	case op == "shrink" && len(args) == 1:
+		max, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+		return pq.ShrinkMax(max)
+	case op == "delmin" && len(args) == 0:
+		_, err := pq.DelMin()
+		return err
+	case op == "clear" && len(args) == 0:
+		pq.Clear()
+		return nil
+	case op == "reverse" && len(args) == 1:
+		reversed, err := strconv.ParseBool(args[0])
+		if err != nil {
+			return err
+		}
+		return pq.SetReversed(reversed)
+	case op == "compact" && len(args) == 0:
+		pq.Compact()
+		return nil
+	}
+	return fmt.Errorf("illegal operation %q", strings.Join(fields, " "))
+}
+
+// parseIndexKey parses the index and the float32 key of a recorded operation.
+func parseIndexKey(args []string) (int, float32, error) {
+	i, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	key, err := strconv.ParseFloat(args[1], 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return i, float32(key), nil
+}
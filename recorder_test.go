@@ -0,0 +1,73 @@
+package heap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	pq.SetRecorder(&log)
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ChangeKey(7, 0.05); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.ChangeKey(3, 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Grow(12); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Set(11, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	pq.SetRecorder(nil)
+	if err := pq.Insert(5, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(log.String(), "insert 0 0\ninsert 1 0.1\n") {
+		t.Fatalf("unexpected log:\n%s", log.String())
+	}
+	r, err := ReplayIndexFibonacciMinPQ(10, strings.NewReader(log.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.Equal(r) {
+		t.Fatalf("expected %v, but got %v", pq.Snapshot(), r.Snapshot())
+	}
+}
+
+func TestReplayError(t *testing.T) {
+	logs := []string{
+		"insert 0 0.5\nfrobnicate 1\n",
+		"insert 0 0.5\ninsert 0 0.4\n",
+		"insert 0\n",
+		"insert 0 x\n",
+		"delmin\n",
+	}
+	for _, log := range logs {
+		if _, err := ReplayIndexFibonacciMinPQ(10, strings.NewReader(log)); err == nil {
+			t.Fatalf("expected error replaying %q", log)
+		}
+	}
+}
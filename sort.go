@@ -0,0 +1,19 @@
+package heap // import "kkn.fi/heap"
+
+// HeapSort returns the indexes that sort the given keys in ascending order.
+// The keys are inserted in a priority queue using their positions as indexes,
+// which is then drained. Equal keys keep their relative order.
+// Returns an error wrapping ErrNaNKey with the position of the first NaN key.
+// Worst case is O(n log(n)).
+func HeapSort(keys []float32) ([]int, error) {
+	pq, err := NewStableIndexFibonacciMinPQ(len(keys))
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		if err := pq.Insert(i, key); err != nil {
+			return nil, err
+		}
+	}
+	return pq.DrainSorted(), nil
+}
@@ -0,0 +1,49 @@
+package heap
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestHeapSort(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 10, 1000} {
+		keys := make([]float32, n)
+		for i := range keys {
+			keys[i] = float32(r.Intn(n/2 + 1))
+		}
+		expected := make([]int, n)
+		for i := range expected {
+			expected[i] = i
+		}
+		sort.SliceStable(expected, func(a, b int) bool {
+			return keys[expected[a]] < keys[expected[b]]
+		})
+		result, err := HeapSort(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(result, expected) {
+			t.Fatalf("expected %v, but got %v", expected, result)
+		}
+	}
+}
+
+func TestHeapSortNaN(t *testing.T) {
+	keys := []float32{0.5, float32(math.NaN()), 0.1}
+	result, err := HeapSort(keys)
+	if !errors.Is(err, ErrNaNKey) || result != nil {
+		t.Fatalf("expected %v, but got %v (%v)", ErrNaNKey, result, err)
+	}
+	if !strings.Contains(err.Error(), "index 1") {
+		t.Fatalf("expected the position of the NaN key, but got %v", err)
+	}
+	if result, err := HeapSort(nil); err != nil || len(result) != 0 {
+		t.Fatalf("expected no indexes, but got %v (%v)", result, err)
+	}
+}
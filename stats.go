@@ -0,0 +1,195 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// HeapStats describes the shape of the Fibonacci heap of a priority queue.
+type HeapStats struct {
+	Trees    int // Number of trees in the root list
+	MaxOrder int // Maximum order of a tree in the root list
+	Marked   int // Number of marked nodes
+	Nodes    int // Total number of nodes
+}
+
+// Stats returns statistics of the shape of the heap.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) Stats() HeapStats {
+	var s HeapStats
+	pq.walk(pq.head, 0, func(x *node[K], depth int) {
+		if depth == 0 {
+			s.Trees++
+			if x.order > s.MaxOrder {
+				s.MaxOrder = x.order
+			}
+		}
+		if x.mark {
+			s.Marked++
+		}
+		s.Nodes++
+	})
+	return s
+}
+
+// MarkedCount returns the number of marked nodes, that lost a child since they were
+// last linked under another node. Roots are never marked in a valid heap.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) MarkedCount() int {
+	n := 0
+	pq.walk(pq.head, 0, func(x *node[K], depth int) {
+		if x.mark {
+			n++
+		}
+	})
+	return n
+}
+
+// OpCounts returns the number of trees linked under another root, the number of nodes cut
+// from their parent and the number of root list consolidations since the priority queue was
+// constructed. The operations are counted only by queues constructed with
+// NewIndexFibonacciPQWithOpCounts, other queues return zeros.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) OpCounts() (links, cuts, consolidations int) {
+	if pq.counts == nil {
+		return 0, 0, 0
+	}
+	return pq.counts.links, pq.counts.cuts, pq.counts.consolidations
+}
+
+// MaxOrderBound returns the maximum possible order of a tree in the heap, that is
+// the largest k such that the (k+2)th Fibonacci number does not exceed Len(), or 0 if empty.
+// Worst case is O(log(n)).
+func (pq IndexFibonacciPQ[K]) MaxOrderBound() int {
+	return orderBound(pq.length)
+}
+
+// ParentKeyOf returns true and the key of the parent of index i in its tree,
+// or false if index i is the root of a tree.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) ParentKeyOf(i int) (hasParent bool, parentKey K, err error) {
+	if !pq.inRange(i) {
+		return false, parentKey, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.Contains(i) {
+		return false, parentKey, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	parent := pq.nodeAt(i).parent
+	if parent == nil {
+		return false, parentKey, nil
+	}
+	return true, parent.key, nil
+}
+
+// IsRoot returns true if index i is the root of a tree in the root list,
+// false if it is the child of another index.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) IsRoot(i int) (bool, error) {
+	if !pq.inRange(i) {
+		return false, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.Contains(i) {
+		return false, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	return pq.nodeAt(i).parent == nil, nil
+}
+
+// NodeView is a snapshot of the node holding an index in the heap.
+type NodeView[K cmp.Ordered] struct {
+	Index  int  // Index associated with the key
+	Key    K    // Key of the node
+	Order  int  // Number of children of the node
+	Marked bool // Indicates if the node lost a child since it was last linked under another node
+	IsRoot bool // Indicates if the node is a root of the root list
+}
+
+// View returns a snapshot of the node holding index i.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) View(i int) (NodeView[K], error) {
+	if !pq.inRange(i) {
+		return NodeView[K]{}, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.Contains(i) {
+		return NodeView[K]{}, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	x := pq.nodeAt(i)
+	return NodeView[K]{
+		Index:  x.index,
+		Key:    x.key,
+		Order:  x.order,
+		Marked: x.mark,
+		IsRoot: x.parent == nil,
+	}, nil
+}
+
+// RootCount returns the number of trees in the root list.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) RootCount() int {
+	if pq.head == nil {
+		return 0
+	}
+	n := 0
+	x := pq.head
+	for ok := true; ok; ok = (x != pq.head) {
+		n++
+		x = x.next
+	}
+	return n
+}
+
+// RootKeys returns the keys of the roots of the trees in the root list, in list order.
+// Worst case is O(r) for r trees in the root list.
+func (pq IndexFibonacciPQ[K]) RootKeys() []K {
+	var result []K
+	if pq.head == nil {
+		return result
+	}
+	x := pq.head
+	for ok := true; ok; ok = (x != pq.head) {
+		result = append(result, x.key)
+		x = x.next
+	}
+	return result
+}
+
+// IsConsolidated returns true if no two trees in the root list have the same order.
+// Worst case is O(r) for r trees in the root list.
+func (pq IndexFibonacciPQ[K]) IsConsolidated() bool {
+	if pq.head == nil {
+		return true
+	}
+	seen := make(map[int]bool)
+	x := pq.head
+	for ok := true; ok; ok = (x != pq.head) {
+		if seen[x.order] {
+			return false
+		}
+		seen[x.order] = true
+		x = x.next
+	}
+	return true
+}
+
+// WalkTrees calls visit with every index in the priority queue along with its key,
+// its depth in its tree and whether it is a root, in depth-first order of the trees
+// of the root list. visit must not modify the priority queue.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) WalkTrees(visit func(index int, key K, depth int, isRoot bool)) {
+	pq.walk(pq.head, 0, func(x *node[K], depth int) {
+		visit(x.index, x.key, depth, depth == 0)
+	})
+}
+
+// walk visits in depth-first order the Nodes of the circular list defined by the head pointer
+// along with their subtrees.
+func (pq IndexFibonacciPQ[K]) walk(head *node[K], depth int, visit func(x *node[K], depth int)) {
+	if head == nil {
+		return
+	}
+	x := head
+	for ok := true; ok; ok = (x != head) {
+		visit(x, depth)
+		pq.walk(x.child, depth+1, visit)
+		x = x.next
+	}
+}
@@ -0,0 +1,521 @@
+package heap
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := pq.Stats(); s != (HeapStats{}) {
+		t.Fatalf("expected zero stats for empty queue, but got %+v", s)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := HeapStats{Trees: 10, MaxOrder: 0, Marked: 0, Nodes: 10}
+	if s := pq.Stats(); s != expected {
+		t.Fatalf("expected %+v, but got %+v", expected, s)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	expected = HeapStats{Trees: 2, MaxOrder: 3, Marked: 0, Nodes: 9}
+	if s := pq.Stats(); s != expected {
+		t.Fatalf("expected %+v, but got %+v", expected, s)
+	}
+	var x *node[float32]
+	for _, n := range pq.nodes {
+		if n != nil && n.parent != nil && n.parent.parent != nil {
+			x = n
+		}
+	}
+	if err := pq.DecreaseKey(x.index, -1); err != nil {
+		t.Fatal(err)
+	}
+	expected = HeapStats{Trees: 3, MaxOrder: 3, Marked: 1, Nodes: 9}
+	if s := pq.Stats(); s != expected {
+		t.Fatalf("expected %+v, but got %+v", expected, s)
+	}
+}
+
+func TestRootCount(t *testing.T) {
+	const k = 10
+	pq, err := NewIndexFibonacciMinPQ(1 << k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := pq.RootCount(); n != 0 {
+		t.Fatalf("expected no roots for empty queue, but got %d", n)
+	}
+	for i := 0; i < 1<<k; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := pq.RootCount(); n != 1<<k {
+		t.Fatalf("expected %d roots, but got %d", 1<<k, n)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if n := pq.RootCount(); n > k || n != pq.Stats().Trees {
+		t.Fatalf("expected at most %d roots, but got %d", k, n)
+	}
+}
+
+func TestWalkTrees(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.DecreaseKey(17, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	nodes, roots, maxDepth := 0, 0, 0
+	pq.WalkTrees(func(i int, key float32, depth int, isRoot bool) {
+		if k, err := pq.KeyOf(i); err != nil || k != key {
+			t.Fatalf("expected key %.1f of %d, but got %.1f (%v)", k, i, key, err)
+		}
+		if isRoot != (depth == 0) {
+			t.Fatalf("expected %d at depth %d to be a root: %v", i, depth, depth == 0)
+		}
+		nodes++
+		if isRoot {
+			roots++
+		}
+		maxDepth = max(maxDepth, depth)
+	})
+	if nodes != pq.Len() || roots != pq.RootCount() {
+		t.Fatalf("expected %d nodes and %d roots, but got %d and %d", pq.Len(), pq.RootCount(), nodes, roots)
+	}
+	if maxDepth == 0 {
+		t.Fatal("expected trees deeper than their roots")
+	}
+}
+
+func TestOpCounts(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQWithOpCounts(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if links, cuts, consolidations := pq.OpCounts(); links != 0 || cuts != 0 || consolidations != 0 {
+		t.Fatalf("expected no operations, but got %d links, %d cuts and %d consolidations", links, cuts, consolidations)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	// Consolidating seven roots links them in trees of order 2, 1 and 0.
+	if links, cuts, consolidations := pq.OpCounts(); links != 4 || cuts != 0 || consolidations != 1 {
+		t.Fatalf("expected 4 links and 1 consolidation, but got %d links, %d cuts and %d consolidations", links, cuts, consolidations)
+	}
+	var x *node[float32]
+	for _, n := range pq.nodes {
+		if n != nil && n.parent != nil {
+			x = n
+		}
+	}
+	if err := pq.DecreaseKey(x.index, -1); err != nil {
+		t.Fatal(err)
+	}
+	if links, cuts, consolidations := pq.OpCounts(); links != 4 || cuts != 1 || consolidations != 1 {
+		t.Fatalf("expected 4 links, 1 cut and 1 consolidation, but got %d links, %d cuts and %d consolidations", links, cuts, consolidations)
+	}
+	plain, err := NewIndexFibonacciMinPQ(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if err := plain.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := plain.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if links, cuts, consolidations := plain.OpCounts(); links != 0 || cuts != 0 || consolidations != 0 {
+		t.Fatalf("expected no counted operations, but got %d links, %d cuts and %d consolidations", links, cuts, consolidations)
+	}
+}
+
+func TestRootKeys(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := pq.RootKeys(); len(keys) != 0 {
+		t.Fatalf("expected no roots for empty queue, but got %v", keys)
+	}
+	for i := 0; i < 12; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := pq.RootKeys(); len(keys) != 12 {
+		t.Fatalf("expected 12 roots, but got %v", keys)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	// 11 keys are consolidated in trees of orders 0, 1 and 3.
+	keys := pq.RootKeys()
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 roots, but got %v", keys)
+	}
+	orders := make(map[int]bool)
+	x := pq.head
+	for j, key := range keys {
+		if x.key != key {
+			t.Fatalf("expected key %f at root %d, but got %f", x.key, j, key)
+		}
+		if orders[x.order] {
+			t.Fatalf("expected distinct orders, but got %d twice", x.order)
+		}
+		orders[x.order] = true
+		x = x.next
+	}
+	if x != pq.head || !orders[0] || !orders[1] || !orders[3] {
+		t.Fatalf("expected orders 0, 1 and 3, but got %v", orders)
+	}
+	if pq.min.key != 1 {
+		t.Fatalf("expected minimum 1, but got %f", pq.min.key)
+	}
+}
+
+func TestIsConsolidated(t *testing.T) {
+	pq, err := NewLazyIndexFibonacciMinPQ(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pq.IsConsolidated() {
+		t.Fatal("expected empty queue to be consolidated")
+	}
+	pq.Consolidate()
+	checkHeap(t, pq)
+	for i := 0; i < 64; i++ {
+		if err := pq.Insert(i, float32((i*37)%64)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pq.IsConsolidated() {
+		t.Fatal("expected inserted roots not to be consolidated")
+	}
+	// The 64 roots exceed the root limit, so the first DelMin consolidates.
+	delMin := func(expected float32) {
+		t.Helper()
+		_, key, err := pq.DelMinWithKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != expected {
+			t.Fatalf("expected key %f, but got %f", expected, key)
+		}
+	}
+	delMin(0)
+	if !pq.IsConsolidated() {
+		t.Fatal("expected queue to be consolidated after DelMin")
+	}
+	// Next DelMins move the children of the minimum to the root list without consolidating.
+	delMin(1)
+	if pq.IsConsolidated() {
+		t.Fatalf("expected lazy DelMin to defer consolidation, but got %d roots", pq.RootCount())
+	}
+	checkHeap(t, pq)
+	pq.Consolidate()
+	if !pq.IsConsolidated() {
+		t.Fatal("expected queue to be consolidated")
+	}
+	checkHeap(t, pq)
+	for expected := 2; expected < 64; expected++ {
+		delMin(float32(expected))
+	}
+	if !pq.IsEmpty() || !pq.IsConsolidated() {
+		t.Fatal("expected empty consolidated queue")
+	}
+}
+
+func TestMaxOrderBound(t *testing.T) {
+	testData := []struct {
+		n, expected int
+	}{
+		{0, 0}, {1, 0}, {2, 1}, {3, 2}, {4, 2}, {5, 3}, {7, 3}, {8, 4}, {1000, 14},
+	}
+	for _, tc := range testData {
+		if k := orderBound(tc.n); k != tc.expected {
+			t.Fatalf("expected bound %d for %d keys, but got %d", tc.expected, tc.n, k)
+		}
+	}
+	const n = 200
+	r := rand.New(rand.NewSource(1))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for m := 0; m < 50*n; m++ {
+		i := r.Intn(n)
+		key := r.Float32()
+		var err error
+		switch {
+		case !pq.Contains(i):
+			err = pq.Insert(i, key)
+		case m%5 == 0:
+			_, err = pq.DelMin()
+		case m%5 == 1:
+			err = pq.Delete(i)
+		default:
+			err = pq.ChangeKey(i, key)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		bound := pq.MaxOrderBound()
+		pq.walk(pq.head, 0, func(x *node[float32], depth int) {
+			if x.order > bound {
+				t.Fatalf("expected order at most %d for %d keys, but got %v", bound, pq.Len(), x)
+			}
+		})
+	}
+	checkHeap(t, pq)
+}
+
+func TestRootKeysDeterministic(t *testing.T) {
+	workload := func() *IndexFibonacciMinPQ {
+		r := rand.New(rand.NewSource(7))
+		pq, err := NewIndexFibonacciMinPQ(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 100; i++ {
+			if err := pq.Insert(i, r.Float32()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for m := 0; m < 30; m++ {
+			if _, err := pq.DelMin(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return pq
+	}
+	pq := workload()
+	keys := pq.RootKeys()
+	for n := 0; n < 10; n++ {
+		if other := workload().RootKeys(); !slices.Equal(keys, other) {
+			t.Fatalf("expected %v, but got %v", keys, other)
+		}
+	}
+	// The root list is rebuilt in ascending order of tree orders.
+	x := pq.head
+	for x.next != pq.head {
+		if x.order >= x.next.order {
+			t.Fatalf("expected ascending orders, but got %v before %v", x, x.next)
+		}
+		x = x.next
+	}
+}
+
+func TestParentKeyOf(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hasParent, _, err := pq.ParentKeyOf(3); err != nil || hasParent {
+		t.Fatalf("expected root before consolidation, but got %t (%v)", hasParent, err)
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	// The 4 remaining keys are consolidated in a single tree rooted by the minimum:
+	// 1 links 2, 3 links 4, then 1 links 3.
+	testData := []struct {
+		index     int
+		hasParent bool
+		parentKey float32
+	}{
+		{1, false, 0},
+		{2, true, 1},
+		{3, true, 1},
+		{4, true, 3},
+	}
+	for _, tc := range testData {
+		hasParent, parentKey, err := pq.ParentKeyOf(tc.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hasParent != tc.hasParent || parentKey != tc.parentKey {
+			t.Fatalf("expected parent %t with key %f for %d, but got %t with key %f",
+				tc.hasParent, tc.parentKey, tc.index, hasParent, parentKey)
+		}
+	}
+	if _, _, err := pq.ParentKeyOf(0); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if _, _, err := pq.ParentKeyOf(10); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+}
+
+func TestIsRoot(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+		isRoot, err := pq.IsRoot(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isRoot {
+			t.Fatalf("expected %d to be a root after insertion", i)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	roots, children := 0, 0
+	for i := 1; i < 8; i++ {
+		isRoot, err := pq.IsRoot(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasParent, _, err := pq.ParentKeyOf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isRoot == hasParent {
+			t.Fatalf("expected %d to be a root if it has no parent, but got %t", i, isRoot)
+		}
+		if isRoot {
+			roots++
+		} else {
+			children++
+		}
+	}
+	if roots != pq.RootCount() || children == 0 {
+		t.Fatalf("expected %d roots and some children, but got %d roots and %d children", pq.RootCount(), roots, children)
+	}
+	if _, err := pq.IsRoot(0); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if _, err := pq.IsRoot(-1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+}
+
+func TestMarkedCount(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 17; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if n := pq.MarkedCount(); n != 0 {
+		t.Fatalf("expected no marked node after consolidation, but got %d", n)
+	}
+	// The 16 remaining keys form a single tree of order 4: its child of order 3
+	// has a parent that is a root and children of its own.
+	var parent *node[float32]
+	x := pq.min.child
+	for ok := true; ok; ok = (x != pq.min.child) {
+		if x.order == 3 {
+			parent = x
+		}
+		x = x.next
+	}
+	if parent == nil {
+		t.Fatalf("expected a child of order 3, but got %v", pq.Stats())
+	}
+	first, second := parent.child.index, parent.child.next.index
+	if err := pq.DecreaseKey(first, -1); err != nil {
+		t.Fatal(err)
+	}
+	if n := pq.MarkedCount(); n != 1 || !parent.mark {
+		t.Fatalf("expected the parent marked after a cut, but got %d marked nodes", n)
+	}
+	if err := pq.DecreaseKey(second, -2); err != nil {
+		t.Fatal(err)
+	}
+	if n := pq.MarkedCount(); n != 0 || parent.parent != nil {
+		t.Fatalf("expected the parent cut and unmarked after a cascading cut, but got %d marked nodes", n)
+	}
+	if n := pq.MarkedCount(); n != pq.Stats().Marked {
+		t.Fatalf("expected %d marked nodes as in Stats, but got %d", pq.Stats().Marked, n)
+	}
+	checkHeap(t, pq)
+}
+
+func TestView(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	// The 8 remaining keys are consolidated in a single tree of order 3 rooted by 1:
+	// 1 links 5, 3 and 2, 5 links 7 and 6, 3 links 4 and 7 links 8.
+	if err := pq.DecreaseKey(8, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	testData := []NodeView[float32]{
+		{Index: 1, Key: 1, Order: 3, Marked: false, IsRoot: true},
+		{Index: 5, Key: 5, Order: 2, Marked: false, IsRoot: false},
+		{Index: 7, Key: 7, Order: 0, Marked: true, IsRoot: false},
+		{Index: 8, Key: 0.5, Order: 0, Marked: false, IsRoot: true},
+		{Index: 4, Key: 4, Order: 0, Marked: false, IsRoot: false},
+	}
+	for _, expected := range testData {
+		v, err := pq.View(expected.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != expected {
+			t.Fatalf("expected %+v, but got %+v", expected, v)
+		}
+	}
+	if _, err := pq.View(0); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if _, err := pq.View(10); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+}
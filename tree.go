@@ -0,0 +1,405 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// tree represents a Fibonacci heap: a collection of heap-ordered trees whose
+// roots are kept in a circular list. It implements the pointer manipulation
+// shared by the priority queues of this package.
+type tree[K cmp.Ordered] struct {
+	head   *node[K]          // Head of the circular root list
+	min    *node[K]          // Minimum Node in the heap
+	length int               // Number of keys in the heap
+	table  []*node[K]        // Roots by order, reused by the consolidate operation
+	less   func(a, b K) bool // Orders the keys, nil orders keys with <
+	rev    bool              // Reverses the order of the keys
+	near   func(a, b K) bool // Reports keys that are equal within epsilon, if not nil, see nearlyEqual
+	ties   TieBreak          // Orders Nodes of equal keys
+	seq    uint64            // Sequence number of the last inserted Node
+	counts *opCounts         // Counts the operations on the heap, if not nil
+	lazy   bool              // Defers the consolidation of the root list after extracting the minimum
+	lesser bool              // Orders Nodes by the Lesser keys carried as their values
+}
+
+// opCounts counts the operations reshaping a heap.
+type opCounts struct {
+	links          int // Number of trees linked under another root
+	cuts           int // Number of Nodes cut from their parent
+	consolidations int // Number of root list consolidations
+}
+
+// TieBreak is a policy ordering the indexes of equal keys in a priority queue.
+type TieBreak int
+
+const (
+	// TieBreakNone leaves the order of equal keys to the shape of the heap.
+	TieBreakNone TieBreak = iota
+	// TieBreakFIFO yields equal keys in insertion order.
+	TieBreakFIFO
+	// TieBreakLIFO yields equal keys in reverse insertion order.
+	TieBreakLIFO
+)
+
+// node represents a node of a tree.
+type node[K cmp.Ordered] struct {
+	key           K        // Key of the Node
+	order         int      // The order of the tree rooted by this Node
+	index         int      // Index associated with the key
+	seq           uint64   // Sequence number of the insertion of the Node
+	value         any      // Value carried by the Node
+	prev, next    *node[K] // siblings of the Node
+	parent, child *node[K] // parent and child of this Node
+	mark          bool     // Indicates if this Node already lost a child
+}
+
+func (n node[K]) String() string {
+	return fmt.Sprintf("node{key=%v,order=%d,index=%d}", n.key, n.order, n.index)
+}
+
+// insert adds a Node to the root list.
+func (t *tree[K]) insert(x *node[K]) {
+	t.length++
+	t.head = t.insertNode(x, t.head)
+	if t.min == nil || t.after(t.min, x) {
+		t.min = x
+	}
+}
+
+// extractMin removes the minimum Node from the heap and returns it.
+// A lazy heap consolidates the root list only when it grows past rootLimit.
+func (t *tree[K]) extractMin() *node[K] {
+	if t.lazy {
+		return t.extractMinLazy(t.rootLimit())
+	}
+	min := t.unlinkMin()
+	if t.length > 0 {
+		t.consolidate()
+	} else {
+		t.min = nil
+	}
+	return min
+}
+
+// extractMinLazy removes the minimum Node from the heap and returns it.
+// The new minimum is found by scanning the root list, which is consolidated
+// only if it holds more than limit trees.
+func (t *tree[K]) extractMinLazy(limit int) *node[K] {
+	min := t.unlinkMin()
+	t.min = nil
+	if t.length == 0 {
+		return min
+	}
+	roots := 0
+	x := t.head
+	for ok := true; ok; ok = (x != t.head) {
+		if t.min == nil || t.after(t.min, x) {
+			t.min = x
+		}
+		roots++
+		x = x.next
+	}
+	if roots > limit {
+		t.consolidate()
+	}
+	return min
+}
+
+// replaceMin removes the minimum Node from the heap, adds Node x to the root list
+// and returns the removed Node. The root list is consolidated once.
+func (t *tree[K]) replaceMin(x *node[K]) *node[K] {
+	min := t.unlinkMin()
+	t.length++
+	t.head = t.insertNode(x, t.head)
+	t.consolidate()
+	return min
+}
+
+// rootLimit returns the number of trees in the root list above which a lazy extraction
+// of the minimum consolidates the root list, twice the number of trees of a consolidated root list.
+func (t *tree[K]) rootLimit() int {
+	return 2 * (orderBound(t.length) + 1)
+}
+
+// unlinkMin removes the minimum Node from the root list, moving its children to the root list.
+// The minimum is left to be restored by the caller.
+func (t *tree[K]) unlinkMin() *node[K] {
+	min := t.min
+	t.head = t.cutNode(min, t.head)
+	if min.child != nil {
+		child := min.child
+		min.child = nil // For garbage collection
+		x := child
+		for ok := true; ok; ok = (x != child) {
+			x.parent = nil
+			x.mark = false
+			x = x.next
+		}
+		t.head = t.meld(t.head, child)
+	}
+	t.length--
+	return min
+}
+
+// decrease sets the key of a Node to the given key, that is not greater than its current key.
+func (t *tree[K]) decrease(x *node[K], key K) {
+	x.key = key
+	if t.after(t.min, x) {
+		t.min = x
+	}
+	if x.parent != nil && t.after(x.parent, x) {
+		t.cut(x)
+	}
+}
+
+// increase sets the key of a Node to the given key, that is not lower than its current key.
+// Only the children that now hold a lower key than the Node are moved to the root list.
+// A Node that is not a root is cut once it has lost more than one child, keeping the order
+// of its tree within orderBound.
+func (t *tree[K]) increase(x *node[K], key K) {
+	x.key = key
+	lost := 0
+	c := x.child
+	for n := x.order; n > 0; n-- {
+		next := c.next
+		if t.after(x, c) {
+			x.child = t.cutNode(c, x.child)
+			x.order--
+			c.parent = nil
+			c.mark = false
+			t.head = t.insertNode(c, t.head)
+			lost++
+		}
+		c = next
+	}
+	if x == t.min {
+		t.consolidate()
+		return
+	}
+	if lost == 0 || x.parent == nil {
+		return
+	}
+	if !x.mark && lost == 1 {
+		x.mark = true
+		return
+	}
+	t.cut(x)
+}
+
+// remove removes a Node from the heap.
+func (t *tree[K]) remove(x *node[K]) {
+	t.detach(x)
+	if t.length > 0 {
+		t.consolidate()
+	} else {
+		t.min = nil
+	}
+}
+
+// detach removes a Node from the heap without consolidating the root list.
+// The minimum is left to be restored by consolidate.
+func (t *tree[K]) detach(x *node[K]) {
+	if x.parent != nil {
+		t.cut(x)
+	}
+	t.head = t.cutNode(x, t.head)
+	if x.child != nil {
+		child := x.child
+		x.child = nil // For garbage collection
+		x = child
+		for ok := true; ok; ok = (child != x) {
+			child.parent = nil
+			child.mark = false
+			child = child.next
+		}
+		t.head = t.meld(t.head, child)
+	}
+	t.length--
+}
+
+// greater compares two keys exactly, regardless of the key epsilon, so that
+// the order shaping the heap is transitive.
+func (t *tree[K]) greater(n K, m K) bool {
+	if t.rev {
+		n, m = m, n
+	}
+	if t.less == nil {
+		return n > m
+	}
+	return t.less(m, n)
+}
+
+// nearlyEqual returns true if two keys are equal within the key epsilon.
+// It only decides whether changing a key is a no-op, never the order of the heap.
+func (t *tree[K]) nearlyEqual(n, m K) bool {
+	return t.near != nil && t.near(n, m)
+}
+
+// after returns true if Node x is ordered after Node y, comparing their keys and
+// breaking ties of equal keys by insertion order according to the tie-break policy.
+func (t *tree[K]) after(x, y *node[K]) bool {
+	if t.lesser {
+		return y.value.(Lesser).Less(x.value)
+	}
+	if t.greater(x.key, y.key) {
+		return true
+	}
+	if t.ties == TieBreakNone || t.greater(y.key, x.key) {
+		return false
+	}
+	if t.ties == TieBreakLIFO {
+		return x.seq < y.seq
+	}
+	return x.seq > y.seq
+}
+
+// link links a new root key. Assuming root1 holds a greater key than root2, root2 becomes the new root
+func (t *tree[K]) link(root1, root2 *node[K]) {
+	root1.parent = root2
+	root1.mark = false
+	root2.child = t.insertNode(root1, root2.child)
+	root2.order++
+	if t.counts != nil {
+		t.counts.links++
+	}
+}
+
+// cut removes a Node from its parent's child list and insert it in the root list.
+// If the parent Node is not a root it is marked. If the parent Node already lost a child,
+// it is cut as well.
+func (t *tree[K]) cut(x *node[K]) {
+	if t.counts != nil {
+		t.counts.cuts++
+	}
+	parent := x.parent
+	parent.child = t.cutNode(x, parent.child)
+	x.parent = nil
+	x.mark = false
+	parent.order--
+	t.head = t.insertNode(x, t.head)
+	if parent.parent == nil {
+		return
+	}
+	if !parent.mark {
+		parent.mark = true
+		return
+	}
+	t.cut(parent)
+}
+
+// consolidate coalesces the roots, thus reshapes the heap.
+// The root list is rebuilt in ascending order of tree orders, so the shape of the heap
+// only depends on the sequence of operations.
+func (t *tree[K]) consolidate() {
+	if t.counts != nil {
+		t.counts.consolidations++
+	}
+	if n := orderBound(t.length) + 1; len(t.table) < n {
+		t.table = make([]*node[K], n)
+	}
+	x := t.head
+	maxOrder := 0
+	var y, z *node[K]
+	for ok := true; ok; ok = (x != t.head) {
+		y = x
+		x = x.next
+		for {
+			if y.order >= len(t.table) {
+				t.table = append(t.table, make([]*node[K], y.order-len(t.table)+1)...)
+			}
+			z = t.table[y.order]
+			if z == nil {
+				break
+			}
+			t.table[y.order] = nil
+			if t.after(y, z) {
+				t.link(y, z)
+				y = z
+			} else {
+				t.link(z, y)
+			}
+		}
+		t.table[y.order] = y
+		if y.order > maxOrder {
+			maxOrder = y.order
+		}
+	}
+	t.head = nil
+	t.min = nil
+	for i, n := range t.table[:maxOrder+1] {
+		if n == nil {
+			continue
+		}
+		if t.min == nil || t.after(t.min, n) {
+			t.min = n
+		}
+		if t.head == nil {
+			t.head = t.insertNode(n, nil)
+		} else {
+			t.insertNode(n, t.head)
+		}
+		t.table[i] = nil // The table is reused, drop the references to the roots
+	}
+}
+
+// orderBound returns the maximum order of a tree in a heap of n keys, that is the largest k
+// such that F(k+2) <= n, or 0 if n is 0, since a tree of order k has at least F(k+2) Nodes,
+// where F(k) is the kth Fibonacci number with F(1) = F(2) = 1. The bound is at most the floor
+// of the logarithm of n in base of the golden ratio.
+func orderBound(n int) int {
+	k := 0
+	for a, b := 2, 3; a <= n; a, b = b, a+b {
+		k++
+	}
+	return k
+}
+
+// insertNode inserts a Node in a circular list containing head, returns a new head.
+func (t *tree[K]) insertNode(x, head *node[K]) *node[K] {
+	if head == nil {
+		x.prev = x
+		x.next = x
+	} else {
+		head.prev.next = x
+		x.next = head
+		x.prev = head.prev
+		head.prev = x
+	}
+	return x
+}
+
+// cutNode removes a tree from the list defined by the head pointer.
+func (t *tree[K]) cutNode(x, head *node[K]) *node[K] {
+	if x.next == x {
+		x.next = nil
+		x.prev = nil
+		return nil
+	}
+	x.next.prev = x.prev
+	x.prev.next = x.next
+	res := x.next
+	x.next = nil
+	x.prev = nil
+	if head == x {
+		return res
+	}
+	return head
+}
+
+// meld concatenates two circular lists, joining the tail of x to y and the tail of y to x,
+// returns the head of the merged list.
+func (t *tree[K]) meld(x, y *node[K]) *node[K] {
+	if x == nil {
+		return y
+	}
+	if y == nil {
+		return x
+	}
+	xt, yt := x.prev, y.prev
+	xt.next = y
+	y.prev = xt
+	yt.next = x
+	x.prev = yt
+	return x
+}
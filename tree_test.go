@@ -0,0 +1,195 @@
+package heap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestMeld(t *testing.T) {
+	var tr tree[float32]
+	list := func(indexes ...int) *node[float32] {
+		var head *node[float32]
+		for _, i := range indexes {
+			x := &node[float32]{index: i}
+			if head == nil {
+				head = tr.insertNode(x, nil)
+			} else {
+				tr.insertNode(x, head)
+			}
+		}
+		return head
+	}
+	testData := []struct {
+		x, y     []int
+		expected []int
+	}{
+		{[]int{0, 1, 2}, []int{3, 4, 5, 6}, []int{0, 1, 2, 3, 4, 5, 6}},
+		{[]int{0}, []int{1, 2}, []int{0, 1, 2}},
+		{[]int{0, 1}, []int{2}, []int{0, 1, 2}},
+		{[]int{0}, nil, []int{0}},
+		{nil, []int{0, 1}, []int{0, 1}},
+	}
+	for _, testCase := range testData {
+		head := tr.meld(list(testCase.x...), list(testCase.y...))
+		var forward, backward []int
+		x := head
+		for ok := true; ok; ok = (x != head) {
+			if x.next.prev != x || x.prev.next != x {
+				t.Fatalf("node %v has inconsistent sibling links", x)
+			}
+			forward = append(forward, x.index)
+			x = x.next
+		}
+		x = head.prev
+		for ok := true; ok; ok = (x != head.prev) {
+			backward = append(backward, x.index)
+			x = x.prev
+		}
+		slices.Reverse(backward)
+		if !slices.Equal(forward, testCase.expected) || !slices.Equal(backward, testCase.expected) {
+			t.Fatalf("expected %v, but got %v and reversed %v", testCase.expected, forward, backward)
+		}
+	}
+}
+
+func TestExtractMinSingleNode(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 2; n++ {
+		if err := pq.Insert(1, 0.5); err != nil {
+			t.Fatal(err)
+		}
+		x := pq.min
+		if y := pq.extractMin(); y != x {
+			t.Fatalf("expected %v, but got %v", x, y)
+		}
+		pq.setNode(1, nil)
+		if !pq.IsEmpty() || pq.head != nil || pq.min != nil {
+			t.Fatalf("expected empty heap, but got length %d, head %v and min %v", pq.Len(), pq.head, pq.min)
+		}
+		if x.next != nil || x.prev != nil || x.parent != nil || x.child != nil {
+			t.Fatalf("expected detached node, but got %+v", *x)
+		}
+		checkHeap(t, pq)
+	}
+	if err := pq.Insert(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if i, err := pq.DelMin(); err != nil || i != 1 {
+		t.Fatalf("expected 1, but got %d (%v)", i, err)
+	}
+	if !pq.IsEmpty() || pq.head != nil || pq.min != nil {
+		t.Fatalf("expected empty heap, but got length %d, head %v and min %v", pq.Len(), pq.head, pq.min)
+	}
+	checkHeap(t, pq)
+}
+
+// consolidateMap coalesces the roots using a map of roots by order,
+// the former implementation of consolidate kept for comparison.
+func (t *tree[K]) consolidateMap() {
+	table := make(map[int]*node[K])
+	x := t.head
+	var y, z *node[K]
+	for ok := true; ok; ok = (x != t.head) {
+		y = x
+		x = x.next
+		z = table[y.order]
+		for z != nil {
+			delete(table, y.order)
+			if t.after(y, z) {
+				t.link(y, z)
+				y = z
+			} else {
+				t.link(z, y)
+			}
+			z = table[y.order]
+		}
+		table[y.order] = y
+	}
+	t.head = nil
+	t.min = nil
+	for _, n := range table {
+		if t.min == nil || t.after(t.min, n) {
+			t.min = n
+		}
+		t.head = t.insertNode(n, t.head)
+	}
+}
+
+func benchmarkConsolidate(b *testing.B, consolidate func(t *tree[float32])) {
+	const n = 1000000
+	r := rand.New(rand.NewSource(1))
+	nodes := make([]node[float32], n)
+	b.ReportAllocs()
+	for m := 0; m < b.N; m++ {
+		b.StopTimer()
+		var tr tree[float32]
+		for i := range nodes {
+			nodes[i] = node[float32]{key: r.Float32(), index: i}
+			tr.insert(&nodes[i])
+		}
+		b.StartTimer()
+		for tr.length > 0 {
+			tr.unlinkMin()
+			if tr.length > 0 {
+				consolidate(&tr)
+			} else {
+				tr.min = nil
+			}
+		}
+	}
+}
+
+func BenchmarkConsolidateSlice(b *testing.B) {
+	benchmarkConsolidate(b, (*tree[float32]).consolidate)
+}
+
+func BenchmarkConsolidateMap(b *testing.B) {
+	benchmarkConsolidate(b, (*tree[float32]).consolidateMap)
+}
+
+func TestDecreaseRootBelowMin(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		if err := pq.Insert(i, float32(i)+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Every key is a root, the last inserted one is the head and index 0 is the minimum.
+	x := pq.nodeAt(4)
+	if x.parent != nil || x == pq.head || x == pq.min {
+		t.Fatal("expected a root other than the head and the minimum")
+	}
+	for _, key := range []float32{0.5, 0.25} {
+		if err := pq.DecreaseKey(4, key); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if pq.head == x || x.parent != nil {
+			t.Fatal("expected the root list to be unchanged")
+		}
+		if i, err := pq.MinIndex(); err != nil || i != 4 {
+			t.Fatalf("expected 4, but got %d (%v)", i, err)
+		}
+		if k, err := pq.MinKey(); err != nil || k != key {
+			t.Fatalf("expected %f, but got %f (%v)", key, k, err)
+		}
+	}
+	expectedDel := []int{4, 0, 1, 2, 3, 5, 6, 7, 8}
+	for _, expected := range expectedDel {
+		i, err := pq.DelMin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+		if expected != i {
+			t.Fatalf("expected %d, but got %d", expected, i)
+		}
+	}
+}
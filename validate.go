@@ -0,0 +1,100 @@
+package heap // import "kkn.fi/heap"
+
+import "fmt"
+
+// Validate checks the invariants of the heap and returns an error describing
+// the first violation found, or nil if the heap is well-formed.
+// Worst case is O(n).
+func (pq IndexFibonacciPQ[K]) Validate() error {
+	if pq.sparse == nil && len(pq.nodes) != pq.max {
+		return fmt.Errorf("index array length %d does not match max %d", len(pq.nodes), pq.max)
+	}
+	for i, x := range pq.sparse {
+		if x == nil || x.index != i {
+			return fmt.Errorf("node %v is stored at index %d", x, i)
+		}
+	}
+	for i, x := range pq.nodes {
+		if x != nil && x.index != i {
+			return fmt.Errorf("node %v is stored at index %d", x, i)
+		}
+	}
+	if pq.head == nil {
+		if pq.length != 0 {
+			return fmt.Errorf("empty heap has length %d", pq.length)
+		}
+		if pq.min != nil {
+			return fmt.Errorf("empty heap has minimum %v", pq.min)
+		}
+		return nil
+	}
+	if pq.min == nil {
+		return fmt.Errorf("non-empty heap has no minimum")
+	}
+	if pq.min.parent != nil {
+		return fmt.Errorf("minimum %v is not a root", pq.min)
+	}
+	n, err := pq.validateList(pq.head, nil, pq.MaxOrderBound())
+	if err != nil {
+		return err
+	}
+	if n != pq.length {
+		return fmt.Errorf("heap has %d nodes but length %d", n, pq.length)
+	}
+	return nil
+}
+
+// validateList checks the invariants of the circular list defined by the head pointer
+// and the subtrees of its Nodes, whose orders must not exceed bound, returns the number of Nodes visited.
+func (pq IndexFibonacciPQ[K]) validateList(head, parent *node[K], bound int) (int, error) {
+	n := 0
+	x := head
+	for ok := true; ok; ok = (x != head) {
+		if x.next == nil || x.prev == nil {
+			return n, fmt.Errorf("node %v has nil sibling links", x)
+		}
+		if x.next.prev != x || x.prev.next != x {
+			return n, fmt.Errorf("node %v has inconsistent sibling links", x)
+		}
+		if x.parent != parent {
+			return n, fmt.Errorf("node %v has parent %v, expected %v", x, x.parent, parent)
+		}
+		if parent == nil && x.mark {
+			return n, fmt.Errorf("root %v is marked", x)
+		}
+		if parent != nil && pq.after(parent, x) {
+			return n, fmt.Errorf("node %v has a lower key than its parent %v", x, parent)
+		}
+		if pq.after(pq.min, x) {
+			return n, fmt.Errorf("node %v has a lower key than minimum %v", x, pq.min)
+		}
+		if !pq.inRange(x.index) || pq.nodeAt(x.index) != x {
+			return n, fmt.Errorf("node %v is not stored at its index", x)
+		}
+		children := 0
+		if x.child != nil {
+			c, err := pq.validateList(x.child, x, bound)
+			if err != nil {
+				return n, err
+			}
+			n += c
+			child := x.child
+			for ok := true; ok; ok = (child != x.child) {
+				children++
+				child = child.next
+			}
+		}
+		if children != x.order {
+			return n, fmt.Errorf("node %v has %d children", x, children)
+		}
+		if x.order > bound {
+			return n, fmt.Errorf("node %v exceeds the maximum order %d", x, bound)
+		}
+		n++
+		if n > pq.length {
+			return n, fmt.Errorf("heap has more nodes than its length %d", pq.length)
+		}
+		x = x.next
+	}
+	return n, nil
+}
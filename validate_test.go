@@ -0,0 +1,78 @@
+package heap
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Validate(); err != nil {
+		t.Fatalf("expected valid empty heap, but got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := pq.Insert(i, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pq.DelMin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pq.Validate(); err != nil {
+		t.Fatalf("expected valid heap, but got %v", err)
+	}
+
+	var x *node[float32]
+	for _, n := range pq.nodes {
+		if n != nil && n.parent != nil {
+			x = n
+		}
+	}
+	key, index, min, prev := x.key, x.index, pq.min, x.prev
+	testData := []struct {
+		name    string
+		corrupt func()
+		restore func()
+	}{
+		{
+			"heap order",
+			func() { x.key = -1 },
+			func() { x.key = key },
+		},
+		{
+			"minimum",
+			func() { pq.min = x },
+			func() { pq.min = min },
+		},
+		{
+			"index",
+			func() { x.index = 0 },
+			func() { x.index = index },
+		},
+		{
+			"sibling links",
+			func() { x.prev = nil },
+			func() { x.prev = prev },
+		},
+		{
+			"order",
+			func() { x.parent.order++ },
+			func() { x.parent.order-- },
+		},
+		{
+			"length",
+			func() { pq.length++ },
+			func() { pq.length-- },
+		},
+	}
+	for _, testCase := range testData {
+		testCase.corrupt()
+		if err := pq.Validate(); err == nil {
+			t.Fatalf("expected %s violation to be detected", testCase.name)
+		}
+		testCase.restore()
+		if err := pq.Validate(); err != nil {
+			t.Fatalf("expected valid heap after restoring %s, but got %v", testCase.name, err)
+		}
+	}
+}
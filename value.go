@@ -0,0 +1,42 @@
+package heap // import "kkn.fi/heap"
+
+import "fmt"
+
+// InsertWithValue associates a key and a value with an index.
+// The value is carried along with the key until the index is deleted; it is copied
+// by Clone but not encoded by the marshaling methods.
+// Worst case is O(1), or O(n) when a bounded priority queue is full.
+func (pq *IndexFibonacciPQ[K]) InsertWithValue(i int, key K, value any) error {
+	if err := pq.Insert(i, key); err != nil {
+		return err
+	}
+	pq.nodeAt(i).value = value
+	return nil
+}
+
+// ValueOf returns the value associated with index i, or nil if it was inserted without a value.
+// Worst case is O(1).
+func (pq IndexFibonacciPQ[K]) ValueOf(i int) (any, error) {
+	if !pq.inRange(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexOutOfRange)
+	}
+	if !pq.Contains(i) {
+		return nil, fmt.Errorf("index %d: %w", i, ErrIndexAbsent)
+	}
+	return pq.nodeAt(i).value, nil
+}
+
+// DelMinValue deletes minimum key, returns the index associated with it,
+// the key deleted and the value associated with the index.
+// Worst case is O(log(n)) (amortized).
+func (pq *IndexFibonacciPQ[K]) DelMinValue() (index int, key K, value any, err error) {
+	if pq.IsEmpty() {
+		return 0, key, nil, ErrEmpty
+	}
+	value = pq.min.value
+	index, key, err = pq.DelMinWithKey()
+	if err != nil {
+		return 0, key, nil, err
+	}
+	return index, key, value, nil
+}
@@ -0,0 +1,67 @@
+package heap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInsertWithValue(t *testing.T) {
+	pq, err := NewIndexFibonacciMinPQ(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 32; i++ {
+		if err := pq.InsertWithValue(i, float32(i)+1, fmt.Sprint("task", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pq.InsertWithValue(3, 0, "duplicate"); !errors.Is(err, ErrIndexPresent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexPresent, err)
+	}
+	if _, err := pq.ValueOf(32); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected %v, but got %v", ErrIndexOutOfRange, err)
+	}
+	if _, _, v, err := pq.DelMinValue(); err != nil || v != "task0" {
+		t.Fatalf("expected task0, but got %v (%v)", v, err)
+	}
+	// Decrease keys deep in the trees so that their Nodes are cut, cascading through marked parents.
+	cuts := 0
+	for i := 31; i > 1; i -= 2 {
+		if pq.nodeAt(i).parent != nil {
+			cuts++
+		}
+		if err := pq.DecreaseKey(i, -float32(i)); err != nil {
+			t.Fatal(err)
+		}
+		checkHeap(t, pq)
+	}
+	if cuts == 0 {
+		t.Fatal("expected DecreaseKey to cut Nodes")
+	}
+	if _, err := pq.ValueOf(0); !errors.Is(err, ErrIndexAbsent) {
+		t.Fatalf("expected %v, but got %v", ErrIndexAbsent, err)
+	}
+	if err := pq.Insert(0, 100); err != nil {
+		t.Fatal(err)
+	}
+	c := pq.Clone()
+	for _, q := range []*IndexFibonacciMinPQ{pq, c} {
+		for !q.IsEmpty() {
+			i, _, v, err := q.DelMinValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := any(fmt.Sprint("task", i))
+			if i == 0 {
+				expected = nil
+			}
+			if v != expected {
+				t.Fatalf("expected %v for %d, but got %v", expected, i, v)
+			}
+		}
+	}
+	if _, _, _, err := pq.DelMinValue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("expected %v, but got %v", ErrEmpty, err)
+	}
+}
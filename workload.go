@@ -0,0 +1,103 @@
+package heap // import "kkn.fi/heap"
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// OpKind is the kind of an operation of a workload.
+type OpKind int
+
+const (
+	// OpInsert inserts a key.
+	OpInsert OpKind = iota
+	// OpDecrease decreases a key.
+	OpDecrease
+	// OpDelMin deletes the minimum key.
+	OpDelMin
+)
+
+// Op is an operation on an indexed priority queue of float32 keys.
+// Index and Key are ignored by OpDelMin.
+type Op struct {
+	Kind  OpKind
+	Index int
+	Key   float32
+}
+
+// String returns the operation in the format written by the recorder of a priority queue.
+func (op Op) String() string {
+	switch op.Kind {
+	case OpInsert:
+		return fmt.Sprintf("insert %d %v", op.Index, op.Key)
+	case OpDecrease:
+		return fmt.Sprintf("decrease %d %v", op.Index, op.Key)
+	case OpDelMin:
+		return "delmin"
+	}
+	return fmt.Sprintf("op(%d)", op.Kind)
+}
+
+// Workload is a sequence of operations on an indexed priority queue of float32 keys.
+type Workload []Op
+
+// RandomWorkload returns a sequence of n insert, decrease and delete-the-minimum operations
+// with indices between 0 and n-1, generated deterministically from the given seed.
+// The operations are valid when applied in order to an empty priority queue:
+// only absent indexes are inserted, only present keys are decreased, and the minimum
+// is deleted only from a non-empty queue.
+// Worst case is O(n log(n)).
+func RandomWorkload(seed int64, n int) Workload {
+	r := rand.New(rand.NewSource(seed))
+	pq, err := NewIndexFibonacciMinPQ(n)
+	if err != nil {
+		return nil
+	}
+	w := make(Workload, 0, n)
+	for len(w) < n {
+		i := r.Intn(n)
+		var op Op
+		switch p := r.Intn(10); {
+		case !pq.Contains(i) && p < 5:
+			op = Op{Kind: OpInsert, Index: i, Key: r.Float32()}
+		case pq.Contains(i) && p < 8:
+			key, _ := pq.KeyOf(i)
+			op = Op{Kind: OpDecrease, Index: i, Key: key * r.Float32()}
+		case !pq.IsEmpty():
+			op = Op{Kind: OpDelMin}
+		default:
+			continue
+		}
+		if err := applyOp(pq, op); err != nil {
+			return nil
+		}
+		w = append(w, op)
+	}
+	return w
+}
+
+// Apply applies the operations of the workload in order to the priority queue.
+// Returns the error of the first operation that fails, along with its position.
+// Worst case is O(m log(n)) (amortized) for m operations.
+func (w Workload) Apply(pq *IndexFibonacciMinPQ) error {
+	for n, op := range w {
+		if err := applyOp(pq, op); err != nil {
+			return fmt.Errorf("operation %d %q: %w", n, op, err)
+		}
+	}
+	return nil
+}
+
+// applyOp applies a single operation to the priority queue.
+func applyOp(pq *IndexFibonacciMinPQ, op Op) error {
+	switch op.Kind {
+	case OpInsert:
+		return pq.Insert(op.Index, op.Key)
+	case OpDecrease:
+		return pq.DecreaseKey(op.Index, op.Key)
+	case OpDelMin:
+		_, err := pq.DelMin()
+		return err
+	}
+	return fmt.Errorf("illegal operation kind %d", op.Kind)
+}
@@ -0,0 +1,65 @@
+package heap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRandomWorkload(t *testing.T) {
+	w := RandomWorkload(1, 1000)
+	if len(w) != 1000 {
+		t.Fatalf("expected 1000 operations, but got %d", len(w))
+	}
+	if other := RandomWorkload(1, 1000); !slices.Equal(w, other) {
+		t.Fatal("expected identical workloads from the same seed")
+	}
+	if other := RandomWorkload(2, 1000); slices.Equal(w, other) {
+		t.Fatal("expected different workloads from different seeds")
+	}
+	kinds := make(map[OpKind]int)
+	for _, op := range w {
+		kinds[op.Kind]++
+	}
+	if kinds[OpInsert] == 0 || kinds[OpDecrease] == 0 || kinds[OpDelMin] == 0 {
+		t.Fatalf("expected every kind of operation, but got %v", kinds)
+	}
+	pq, err := NewIndexFibonacciMinPQ(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	pq.SetRecorder(&log)
+	if err := w.Apply(pq); err != nil {
+		t.Fatal(err)
+	}
+	checkHeap(t, pq)
+	var expected strings.Builder
+	for _, op := range w {
+		expected.WriteString(op.String() + "\n")
+	}
+	if log.String() != expected.String() {
+		t.Fatal("expected the recorded operations to match the workload")
+	}
+	if err := w.Apply(pq); err == nil {
+		t.Fatal("expected error applying the workload twice")
+	}
+}
+
+func BenchmarkRandomWorkload(b *testing.B) {
+	const n = 10000
+	w := RandomWorkload(1, n)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for m := 0; m < b.N; m++ {
+		b.StopTimer()
+		pq, err := NewIndexFibonacciMinPQ(n)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err := w.Apply(pq); err != nil {
+			b.Fatal(err)
+		}
+	}
+}